@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Bookmarks keep a copy of the pattern at a generation, so it can be
+// gone back to long after it's dropped out of the history.  They belong
+// to the tab, and only last as long as it does.  Once the pattern has
+// been edited or replaced a bookmark is from an earlier pattern, and it's
+// only gone to when it's picked from the list.  There's no going to a
+// bookmark while comparing, since the comparison would be left behind at
+// another generation.
+
+type Bookmark struct {
+	Name    string
+	Pattern *Pattern // a copy of the pattern at the bookmarked generation
+	lineage int64    // the sim's lineage when the bookmark was made
+}
+
+var lineages atomic.Int64 // for handing out new lineages
+
+// newLineage marks the cells as changed, other than by stepping
+func (ls *LifeSim) newLineage() {
+	ls.lineage = lineages.Add(1)
+}
+
+// IsCurrent returns whether the bookmark is from the pattern the sim has
+// now, rather than one from before an edit or load
+func (bookmark *Bookmark) IsCurrent(ls *LifeSim) bool {
+	return bookmark.lineage == ls.lineage
+}
+
+func (bookmark *Bookmark) Generation() int {
+	return bookmark.Pattern.Game.Generation
+}
+
+// AddBookmark snapshots the current generation
+func (lc *LifeContainer) AddBookmark(name string) {
+	lc.Bookmarks = append(lc.Bookmarks, &Bookmark{Name: name, Pattern: lc.Sim.Pattern().Copy(), lineage: lc.Sim.lineage})
+}
+
+// GoToBookmark puts the sim back to the bookmarked generation, still
+// looking at the same part of the board.  The cell edits made to the
+// game it replaces can't be undone on the bookmark's copy, so they're
+// forgotten.
+func (lc *LifeContainer) GoToBookmark(bookmark *Bookmark) error {
+	if lc.IsComparing() {
+		return errors.New("Stop comparing to go to a bookmark, so the comparison isn't left at another generation.")
+	}
+	viewMin, viewMax := lc.Sim.BoxDisplayMin, lc.Sim.BoxDisplayMax
+	lc.replacePattern(bookmark.Pattern.Copy())
+	lc.Sim.Edits.ForgetCellEdits()
+	lc.Sim.lineage = bookmark.lineage // so the other bookmarks of that pattern apply again
+	lc.Sim.SetDisplayBox(viewMin, viewMax)
+	lc.Sim.menuUpdate()
+	return nil
+}
+
+func (lc *LifeContainer) DeleteBookmark(bookmark *Bookmark) {
+	for index, other := range lc.Bookmarks {
+		if other == bookmark {
+			lc.Bookmarks = append(lc.Bookmarks[:index], lc.Bookmarks[index+1:]...)
+			return
+		}
+	}
+}
+
+// ShowAddBookmarkDialog asks for a name for a bookmark of the current
+// generation
+func ShowAddBookmarkDialog(lc *LifeContainer) {
+	lc.Control.StopSim()
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText(fmt.Sprintf("Generation %d", lc.Sim.Game.Generation))
+	formItems := []*widget.FormItem{widget.NewFormItem("Name", nameEntry)}
+	dialog.ShowForm("Add bookmark", "Add", "Cancel", formItems, func(confirmed bool) {
+		name := strings.TrimSpace(nameEntry.Text)
+		if confirmed && name != "" {
+			lc.AddBookmark(name)
+		}
+	}, mainWindow)
+}
+
+// ShowBookmarksDialog lists the tab's bookmarks to go to or delete
+func ShowBookmarksDialog(lc *LifeContainer) {
+	if len(lc.Bookmarks) == 0 {
+		dialog.ShowInformation("No bookmarks", "Use Add Bookmark to bookmark the current generation.", mainWindow)
+		return
+	}
+
+	var selected *Bookmark
+	list := widget.NewList(func() int {
+		return len(lc.Bookmarks)
+	}, func() fyne.CanvasObject {
+		return widget.NewLabel("")
+	}, func(id widget.ListItemID, obj fyne.CanvasObject) {
+		if id < len(lc.Bookmarks) {
+			bookmark := lc.Bookmarks[id]
+			text := fmt.Sprintf("%s (generation %d)", bookmark.Name, bookmark.Generation())
+			if !bookmark.IsCurrent(lc.Sim) {
+				text += ", before the last change"
+			}
+			obj.(*widget.Label).SetText(text)
+		}
+	})
+	list.OnSelected = func(id widget.ListItemID) {
+		if id < len(lc.Bookmarks) {
+			selected = lc.Bookmarks[id]
+		}
+	}
+	list.OnUnselected = func(_ widget.ListItemID) {
+		selected = nil
+	}
+
+	var bookmarks *dialog.CustomDialog
+	goButton := widget.NewButtonWithIcon("Go To", theme.MediaReplayIcon(), func() {
+		if selected != nil {
+			if err := lc.GoToBookmark(selected); err != nil {
+				dialog.ShowError(err, mainWindow)
+				return
+			}
+			bookmarks.Hide()
+		}
+	})
+	deleteButton := widget.NewButtonWithIcon("Delete", theme.DeleteIcon(), func() {
+		if selected != nil {
+			lc.DeleteBookmark(selected)
+			selected = nil
+			list.UnselectAll()
+			list.Refresh()
+		}
+	})
+	buttons := container.New(layout.NewHBoxLayout(), goButton, deleteButton)
+
+	bookmarks = dialog.NewCustom("Bookmarks", "Close", container.NewBorder(nil, buttons, nil, nil, list), mainWindow)
+	bookmarks.Resize(fyne.NewSize(400, 400))
+	bookmarks.Show()
+}
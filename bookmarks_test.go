@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/pneumaticdeath/golife"
+
+	"fyne.io/fyne/v2/test"
+)
+
+func newBookmarkTestContainer(t *testing.T) *LifeContainer {
+	InitConfig(test.NewApp())
+	lc := NewLifeContainer(func() {})
+	lc.SetPattern(newTestPattern(testBlinker...))
+	return lc
+}
+
+func TestGoToBookmarkForgetsCellEdits(t *testing.T) {
+	lc := newBookmarkTestContainer(t)
+	lc.AddBookmark("start")
+	lc.Sim.Game.AddCell(golife.Cell{X: 9, Y: 9})
+	lc.Sim.RecordEdit(Edit{Added: []golife.Cell{{X: 9, Y: 9}}})
+
+	if err := lc.GoToBookmark(lc.Bookmarks[0]); err != nil {
+		t.Fatal(err)
+	}
+	if lc.Sim.Edits.CanUndo(lc.Sim.Game) {
+		t.Error("an edit to the replaced game can be undone on the bookmark's copy")
+	}
+	if lc.Sim.Game.HasCell(golife.Cell{X: 9, Y: 9}) {
+		t.Error("the bookmark has the edit in it")
+	}
+}
+
+func TestBookmarksNotUsedWhileComparing(t *testing.T) {
+	lc := newBookmarkTestContainer(t)
+	lc.AddBookmark("start")
+	lc.StartCompare(newTestPattern(testBlinker...))
+	for range Config.HistorySize() + 5 {
+		lc.Sim.Step()
+		lc.Sim.Compare.stepWith(lc.Sim)
+	}
+
+	if err := lc.GoToBookmark(lc.Bookmarks[0]); err == nil {
+		t.Error("went to a bookmark while comparing")
+	}
+	if err := lc.rewindFor(0); err == nil {
+		t.Error("rewound past the history while comparing")
+	}
+	if lc.Sim.Game.Generation != lc.Sim.Compare.Game.Generation {
+		t.Errorf("sim is at generation %d and the comparison at %d", lc.Sim.Game.Generation, lc.Sim.Compare.Game.Generation)
+	}
+}
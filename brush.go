@@ -0,0 +1,185 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/pneumaticdeath/golife"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+)
+
+// The edit tools decide what dragging does in edit mode.  Select sizes a
+// selection rectangle, the brush tools paint or erase cells along the
+// drag, and the shape tools draw lines and boxes.  Holding shift swaps
+// drawing and erasing for a stroke, but since there's no shift key on
+// touch devices they can also be picked in the ControlBar.  Each stroke
+// is undone as a single edit.
+
+const (
+	editToolSelect = "Select"
+	editToolDraw   = "Draw"
+	editToolErase  = "Erase"
+)
+
+var editTools = []string{editToolSelect, editToolDraw, editToolErase, editToolLine, editToolDiagonal,
+	editToolRectangle, editToolFilledRectangle, editToolEllipse}
+
+const (
+	brushSquare = "Square"
+	brushCircle = "Circle"
+)
+
+var brushSizes = []int{1, 2, 3, 5, 8, 13}
+
+// CellStates maps cells to their states, including dead ones
+type CellStates map[golife.Cell]uint8
+
+func brushSizeName(size int) string {
+	return "Brush " + strconv.Itoa(size)
+}
+
+// brushOffsets returns the cells the brush covers, relative to the cell
+// it's centered on.
+func brushOffsets(size int, shape string) []golife.Cell {
+	size = max(size, 1)
+	radius := float64(size)/2 - 0.25 // so a circle of 3 is a plus rather than a square
+	center := float64(size-1) / 2
+	offsets := make([]golife.Cell, 0, size*size)
+	for i := 0; i < size; i++ {
+		for j := 0; j < size; j++ {
+			x, y := float64(i)-center, float64(j)-center
+			if shape == brushCircle && x*x+y*y > radius*radius {
+				continue
+			}
+			offsets = append(offsets, golife.Cell{X: golife.Coord(i - (size-1)/2), Y: golife.Coord(j - (size-1)/2)})
+		}
+	}
+	return offsets
+}
+
+// cellsOnLine returns the cells on a straight line between two cells,
+// including both ends.
+func cellsOnLine(from, to golife.Cell) []golife.Cell {
+	dx, dy := abs(to.X-from.X), -abs(to.Y-from.Y)
+	stepX, stepY := golife.Coord(1), golife.Coord(1)
+	if to.X < from.X {
+		stepX = -1
+	}
+	if to.Y < from.Y {
+		stepY = -1
+	}
+	cells := make([]golife.Cell, 0, max(dx, -dy)+1)
+	err := dx + dy
+	for cell := from; ; {
+		cells = append(cells, cell)
+		if cell == to {
+			return cells
+		}
+		doubled := 2 * err // both steps have to compare against the error before either
+		if doubled >= dy {
+			err += dy
+			cell.X += stepX
+		}
+		if doubled <= dx {
+			err += dx
+			cell.Y += stepY
+		}
+	}
+}
+
+// shiftHeld reports whether the shift key is down, which is never on
+// devices without a keyboard.
+func shiftHeld() bool {
+	if drv, ok := fyne.CurrentApp().Driver().(desktop.Driver); ok {
+		return drv.CurrentKeyModifiers()&fyne.KeyModifierShift != 0
+	}
+	return false
+}
+
+// brushState is the state the brush paints, 0 when it's erasing
+func (ls *LifeSim) brushState() uint8 {
+	erasing := ls.EditTool == editToolErase
+	if shiftHeld() {
+		erasing = !erasing
+	}
+	if erasing {
+		return 0
+	}
+	return ls.paintingState()
+}
+
+// paintingState is the state a tap paints, which is always alive unless
+// a Generations rule has dying states to pick from
+func (ls *LifeSim) paintingState() uint8 {
+	if !ls.Rule.IsGenerations() || int(ls.PaintState) >= ls.Rule.States {
+		return 1
+	}
+	return ls.PaintState
+}
+
+func (ls *LifeSim) usingBrush() bool {
+	return ls.EditTool == editToolDraw || ls.EditTool == editToolErase
+}
+
+// brushStroke paints along the drag from wherever it last got to
+func (ls *LifeSim) brushStroke(e *fyne.DragEvent) {
+	if ls.strokeBefore == nil {
+		ls.startStroke(ls.CellAt(e.Position.Subtract(e.Dragged)))
+	}
+	end := ls.CellAt(e.Position)
+	state := ls.brushState()
+	offsets := brushOffsets(Config.BrushSize(), Config.BrushShape())
+	for _, cell := range cellsOnLine(ls.strokeLast, end) {
+		ls.paintBrush(cell, state, offsets)
+	}
+	ls.strokeLast = end
+	ls.Dirty = true
+}
+
+// brushDab paints a single spot, for a tap with a brush tool
+func (ls *LifeSim) brushDab(pos fyne.Position) {
+	cell := ls.CellAt(pos)
+	ls.startStroke(cell)
+	ls.paintBrush(cell, ls.brushState(), brushOffsets(Config.BrushSize(), Config.BrushShape()))
+	ls.endStroke()
+	ls.Dirty = true
+}
+
+func (ls *LifeSim) startStroke(at golife.Cell) {
+	ls.SyncPopulation()
+	ls.strokeBefore = make(CellStates)
+	ls.strokeLast = at
+}
+
+func (ls *LifeSim) paintBrush(center golife.Cell, state uint8, offsets []golife.Cell) {
+	for _, offset := range offsets {
+		cell := golife.Cell{X: center.X + offset.X, Y: center.Y + offset.Y}
+		if _, seen := ls.strokeBefore[cell]; !seen {
+			ls.strokeBefore[cell] = ls.CellState(cell)
+		}
+		ls.SetCellState(cell, state)
+	}
+}
+
+// endStroke records everything the stroke changed as one edit
+func (ls *LifeSim) endStroke() {
+	ls.RecordEdit(ls.finishStroke())
+}
+
+// finishStroke ends the stroke, returning everything it changed as one
+// edit that's yet to be recorded
+func (ls *LifeSim) finishStroke() Edit {
+	var stroke Edit
+	if ls.strokeBefore == nil {
+		return stroke
+	}
+	for cell, before := range ls.strokeBefore {
+		edit := CellStateEdit(cell, before, ls.CellState(cell))
+		stroke.Added = append(stroke.Added, edit.Added...)
+		stroke.Removed = append(stroke.Removed, edit.Removed...)
+		stroke.Dying = append(stroke.Dying, edit.Dying...)
+	}
+	ls.strokeBefore = nil
+	return stroke
+}
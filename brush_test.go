@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/pneumaticdeath/golife"
+)
+
+func TestCellsOnLine(t *testing.T) {
+	tests := []struct {
+		from, to golife.Cell
+		want     int
+	}{
+		{golife.Cell{X: 0, Y: 0}, golife.Cell{X: 0, Y: 0}, 1},
+		{golife.Cell{X: 0, Y: 0}, golife.Cell{X: 4, Y: 0}, 5},
+		{golife.Cell{X: 0, Y: 0}, golife.Cell{X: 4, Y: 2}, 5},
+		{golife.Cell{X: 3, Y: 7}, golife.Cell{X: -2, Y: -1}, 9},
+		{golife.Cell{X: 0, Y: 0}, golife.Cell{X: -3, Y: 3}, 4},
+	}
+	for _, test := range tests {
+		cells := cellsOnLine(test.from, test.to)
+		if len(cells) != test.want || cells[0] != test.from || cells[len(cells)-1] != test.to {
+			t.Errorf("line from %v to %v: got %v, want %d cells", test.from, test.to, cells, test.want)
+			continue
+		}
+		for i := 1; i < len(cells); i++ {
+			if abs(cells[i].X-cells[i-1].X) > 1 || abs(cells[i].Y-cells[i-1].Y) > 1 {
+				t.Errorf("line from %v to %v has a gap at %v", test.from, test.to, cells[i])
+			}
+		}
+	}
+}
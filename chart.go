@@ -0,0 +1,350 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pneumaticdeath/golife"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// The ChartPanel plots how the population, the size of the bounding box
+// and the step time change over the generations.  The sim records a
+// sample every time it steps, so none are skipped however fast it runs,
+// but only measures the bounding box at the chart's cadence, and the
+// chart only redraws from its own goroutine, like the StatusBar, so
+// drawing never slows down the simulation.  Edits, loads and steps back
+// are sampled by that goroutine too, since the sim doesn't step.
+
+const (
+	chartUpdateCadence = 100 * time.Millisecond
+	maxChartSamples    = 20000 // thin out the samples when we have this many
+	chartMinSpan       = 10    // the narrowest span of generations we zoom in to
+)
+
+type StatSample struct {
+	Generation int
+	Population int
+	Width      golife.Coord
+	Height     golife.Coord
+	StepTime   time.Duration
+}
+
+type chartSeries struct {
+	name  string
+	color color.NRGBA
+	value func(s StatSample) float64
+}
+
+var chartSeriesList = []chartSeries{
+	{"Population", color.NRGBA{R: 0, G: 200, B: 0, A: 255}, func(s StatSample) float64 { return float64(s.Population) }},
+	{"Width", color.NRGBA{R: 255, G: 140, B: 0, A: 255}, func(s StatSample) float64 { return float64(s.Width) }},
+	{"Height", color.NRGBA{R: 220, G: 0, B: 220, A: 255}, func(s StatSample) float64 { return float64(s.Height) }},
+	{"Step time", color.NRGBA{R: 0, G: 180, B: 255, A: 255}, func(s StatSample) float64 { return s.StepTime.Seconds() }},
+}
+
+// StatsHistory is the list of samples, shared between the sampling
+// goroutine and the UI.
+type StatsHistory struct {
+	lock    sync.Mutex
+	samples []StatSample
+	changes int // how many samples have been added, so the chart knows when to redraw
+}
+
+// Add records a sample.  Going back to an earlier generation (stepping
+// back or loading a new game) throws away the samples after it, and a
+// sample for the same generation (an edit) replaces the last one.
+func (h *StatsHistory) Add(sample StatSample) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.changes++
+	for len(h.samples) > 0 && h.samples[len(h.samples)-1].Generation >= sample.Generation {
+		h.samples = h.samples[:len(h.samples)-1]
+	}
+	h.samples = append(h.samples, sample)
+	if len(h.samples) >= maxChartSamples {
+		// keep every other sample, but always the latest
+		thinned := h.samples[:0]
+		for index := 0; index < len(h.samples)-1; index += 2 {
+			thinned = append(thinned, h.samples[index])
+		}
+		h.samples = append(thinned, sample)
+	}
+}
+
+func (h *StatsHistory) Samples() []StatSample {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return append([]StatSample(nil), h.samples...)
+}
+
+func (h *StatsHistory) Changes() int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.changes
+}
+
+// LastGeneration returns the generation of the latest sample, or -1
+func (h *StatsHistory) LastGeneration() int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if len(h.samples) == 0 {
+		return -1
+	}
+	return h.samples[len(h.samples)-1].Generation
+}
+
+func (h *StatsHistory) WriteCSV(writer io.Writer) error {
+	out := bufio.NewWriter(writer)
+	fmt.Fprintln(out, "generation,population,width,height,step_time_ns")
+	for _, s := range h.Samples() {
+		fmt.Fprintf(out, "%d,%d,%d,%d,%d\n", s.Generation, s.Population, s.Width, s.Height, s.StepTime.Nanoseconds())
+	}
+	return out.Flush()
+}
+
+type ChartPanel struct {
+	widget.BaseWidget
+	life           *LifeSim
+	History        *StatsHistory
+	raster         *canvas.Raster
+	shown          []bool // which of chartSeriesList to draw
+	span           int    // how many of the latest generations to show, 0 for all of them
+	rangeDisplay   *widget.Label
+	content        *fyne.Container
+	ClockRunning   bool
+	sampledLineage int64 // the sim's lineage when it was last sampled, to notice edits
+}
+
+func NewChartPanel(sim *LifeSim) *ChartPanel {
+	cp := &ChartPanel{life: sim, History: &StatsHistory{}, ClockRunning: true}
+	sim.Stats = cp.History
+	cp.shown = make([]bool, len(chartSeriesList))
+	cp.shown[0] = true
+
+	cp.raster = canvas.NewRaster(cp.render)
+	cp.raster.SetMinSize(fyne.NewSize(200, 150))
+
+	legend := container.New(layout.NewHBoxLayout())
+	for index, series := range chartSeriesList {
+		swatch := canvas.NewRectangle(series.color)
+		swatch.SetMinSize(fyne.NewSize(12, 12))
+		check := widget.NewCheck(series.name, func(checked bool) {
+			cp.shown[index] = checked
+			cp.raster.Refresh()
+		})
+		check.SetChecked(cp.shown[index])
+		legend.Add(container.NewCenter(swatch))
+		legend.Add(check)
+	}
+
+	cp.rangeDisplay = widget.NewLabel("")
+	zoomInButton := widget.NewButtonWithIcon("", theme.ZoomInIcon(), func() {
+		samples := cp.History.Samples()
+		if cp.span == 0 && len(samples) > 1 {
+			cp.span = samples[len(samples)-1].Generation - samples[0].Generation
+		}
+		cp.span = max(chartMinSpan, cp.span/2)
+		cp.Update()
+	})
+	zoomOutButton := widget.NewButtonWithIcon("", theme.ZoomOutIcon(), func() {
+		if cp.span != 0 {
+			cp.span *= 2
+		}
+		cp.Update()
+	})
+	allButton := widget.NewButton("All", func() {
+		cp.span = 0
+		cp.Update()
+	})
+	exportButton := widget.NewButtonWithIcon("CSV", theme.DocumentSaveIcon(), func() {
+		cp.ExportCSV()
+	})
+
+	controls := container.New(layout.NewHBoxLayout(), legend, layout.NewSpacer(), cp.rangeDisplay,
+		zoomInButton, zoomOutButton, allButton, exportButton)
+	cp.content = container.NewBorder(controls, nil, nil, nil, cp.raster)
+
+	cp.ExtendBaseWidget(cp)
+
+	go func() {
+		drawn := -1
+		for cp.ClockRunning {
+			cp.sampleChanges()
+			if changes := cp.History.Changes(); changes != drawn {
+				drawn = changes
+				fyne.Do(func() {
+					if cp.Visible() {
+						cp.Update()
+					}
+				})
+			}
+			time.Sleep(chartUpdateCadence)
+		}
+	}()
+
+	return cp
+}
+
+func (cp *ChartPanel) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(cp.content)
+}
+
+// recordStats adds a sample of the sim as it is now to its stats, if
+// they're being kept.  Measuring the bounding box means going over every
+// cell, so it's only done when measure is set, and the samples in
+// between get the size it was last measured at.
+func (ls *LifeSim) recordStats(measure bool) {
+	if ls.Stats == nil {
+		return
+	}
+	s := StatSample{Generation: ls.Game.Generation, Population: ls.CellCount(), StepTime: ls.LastStepTime}
+	if measure {
+		ls.statsSize = golife.Cell{}
+		if s.Population > 0 {
+			minCell, maxCell := ls.BoundingBox()
+			ls.statsSize = golife.Cell{X: maxCell.X - minCell.X + 1, Y: maxCell.Y - minCell.Y + 1}
+		}
+		ls.statsMeasured = time.Now()
+	}
+	s.Width, s.Height = ls.statsSize.X, ls.statsSize.Y
+	ls.Stats.Add(s)
+}
+
+// sampleChanges samples the sim when it's changed without stepping,
+// like going back a generation, loading a game or editing it.
+func (cp *ChartPanel) sampleChanges() {
+	if cp.life.GetState() == simRunning {
+		return // it's sampling every step
+	}
+	lineage := cp.life.lineage
+	if cp.life.Game.Generation != cp.History.LastGeneration() || lineage != cp.sampledLineage {
+		cp.sampledLineage = lineage
+		cp.life.recordStats(true)
+	}
+}
+
+// Update redraws the chart and its range label
+func (cp *ChartPanel) Update() {
+	samples, last := cp.visibleSamples()
+	if len(samples) > 0 {
+		cp.rangeDisplay.SetText(fmt.Sprintf("Generations %d - %d", samples[0].Generation, last.Generation))
+	} else {
+		cp.rangeDisplay.SetText("")
+	}
+	cp.raster.Refresh()
+}
+
+// visibleSamples returns the samples in the span being shown, and the last one
+func (cp *ChartPanel) visibleSamples() ([]StatSample, StatSample) {
+	samples := cp.History.Samples()
+	if len(samples) == 0 {
+		return nil, StatSample{}
+	}
+	last := samples[len(samples)-1]
+	if cp.span > 0 {
+		start := 0
+		for start < len(samples)-1 && samples[start].Generation < last.Generation-cp.span {
+			start++
+		}
+		samples = samples[start:]
+	}
+	return samples, last
+}
+
+// render draws each series scaled to its own maximum, since they're all
+// in different units.
+func (cp *ChartPanel) render(width, height int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	bg := color.NRGBAModel.Convert(Config.BackgroundColor()).(color.NRGBA)
+	for index := 0; index < len(img.Pix); index += 4 {
+		img.Pix[index], img.Pix[index+1], img.Pix[index+2], img.Pix[index+3] = bg.R, bg.G, bg.B, bg.A
+	}
+
+	samples, last := cp.visibleSamples()
+	if len(samples) < 2 || width < 2 || height < 2 {
+		return img
+	}
+	firstGen := samples[0].Generation
+	genSpan := float64(max(1, last.Generation-firstGen))
+
+	for index, series := range chartSeriesList {
+		if !cp.shown[index] {
+			continue
+		}
+		top := 0.0
+		for _, s := range samples {
+			top = max(top, series.value(s))
+		}
+		if top == 0 {
+			top = 1
+		}
+		px := func(s StatSample) (int, int) {
+			x := int(float64(s.Generation-firstGen) / genSpan * float64(width-1))
+			y := height - 1 - int(series.value(s)/top*float64(height-1))
+			return x, y
+		}
+		x0, y0 := px(samples[0])
+		for _, s := range samples[1:] {
+			x1, y1 := px(s)
+			drawLine(img, x0, y0, x1, y1, series.color)
+			x0, y0 = x1, y1
+		}
+	}
+	return img
+}
+
+func drawLine(img *image.NRGBA, x0, y0, x1, y1 int, clr color.NRGBA) {
+	steps := max(abs(x1-x0), abs(y1-y0), 1)
+	for step := 0; step <= steps; step++ {
+		img.SetNRGBA(x0+(x1-x0)*step/steps, y0+(y1-y0)*step/steps, clr)
+	}
+}
+
+func abs[N ~int | ~int64](n N) N {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// ExportCSV saves all of the samples to a CSV file
+func (cp *ChartPanel) ExportCSV() {
+	fileSave := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, mainWindow)
+			return
+		}
+		if writer == nil {
+			return
+		}
+		defer writer.Close()
+		if err := cp.History.WriteCSV(writer); err != nil {
+			dialog.ShowError(err, mainWindow)
+		}
+		if writer.URI().Scheme() == "file" {
+			Config.SetLastUsedDirURI(writer.URI())
+		}
+	}, mainWindow)
+	fileSave.SetFilter(&LongExtensionsFileFilter{Extensions: []string{".csv"}})
+	fileSave.SetFileName(strings.ReplaceAll(gameTitle(cp.life.Game), " ", "_") + "_stats.csv")
+	fileSave.SetLocation(Config.LastUsedDirURI())
+	fileSave.Show()
+}
+
+func (cp *ChartPanel) StopClocks() {
+	cp.ClockRunning = false
+}
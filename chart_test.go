@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/pneumaticdeath/golife"
+
+	"fyne.io/fyne/v2/test"
+)
+
+func TestStepRecordsStats(t *testing.T) {
+	InitConfig(test.NewApp())
+	sim := NewLifeSim(func() {})
+	sim.Game.AddCells(testBlinker)
+	sim.Stats = &StatsHistory{}
+	for range 5 {
+		sim.Step()
+	}
+	samples := sim.Stats.Samples()
+	if len(samples) != 5 {
+		t.Fatalf("got %d samples, want one a step", len(samples))
+	}
+	for index, sample := range samples {
+		if sample.Generation != index+1 || sample.Population != 3 {
+			t.Errorf("sample %d is %+v", index, sample)
+		}
+		// the box is only measured on the first step, this quickly
+		if sample.Width != 3 || sample.Height != 1 {
+			t.Errorf("sample %d is %dx%d, want the size measured at the first step", index, sample.Width, sample.Height)
+		}
+	}
+}
+
+func TestSampleChangesOnlyWhenChanged(t *testing.T) {
+	InitConfig(test.NewApp())
+	sim := NewLifeSim(func() {})
+	sim.Game.AddCells(testBlinker)
+	cp := &ChartPanel{life: sim, History: &StatsHistory{}}
+	sim.Stats = cp.History
+	sim.SetState(simEditing)
+
+	cp.sampleChanges()
+	cp.sampleChanges()
+	if changes := cp.History.Changes(); changes != 1 {
+		t.Errorf("got %d samples of an unchanged sim, want 1", changes)
+	}
+
+	sim.Game.AddCell(golife.Cell{X: 5, Y: 5})
+	sim.RecordEdit(Edit{Added: []golife.Cell{{X: 5, Y: 5}}})
+	cp.sampleChanges()
+	samples := cp.History.Samples()
+	if cp.History.Changes() != 2 || len(samples) != 1 || samples[0].Population != 4 {
+		t.Errorf("edit wasn't sampled in place of the last sample: %+v", samples)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pneumaticdeath/golife"
+)
+
+// Headless mode, for running patterns in batch jobs on machines without
+// a display, e.g.
+//
+//	gooeylife run --gens 10000 --in pattern.rle --out result.rle --stats stats.csv
+//
+// None of this touches the Fyne app (or Config, which needs it), so it
+// works without any windowing system at all.
+
+// runCommand handles the command line if it asks for a subcommand,
+// returning false if the GUI should start as usual.
+func runCommand(args []string) (bool, int) {
+	if len(args) == 0 || args[0] != "run" {
+		return false, 0
+	}
+	err := runHeadless(args[1:], os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gooeylife run:", err)
+		return true, 1
+	}
+	return true, 0
+}
+
+func runHeadless(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	gens := flags.Int("gens", 100, "number of generations to run")
+	inFile := flags.String("in", "", "pattern file to load (.rle, .life, .cells or .mc)")
+	outFile := flags.String("out", "", "file to write the final pattern to (.rle or .mc), default is standard output")
+	statsFile := flags.String("stats", "", "CSV file to write statistics to, a row per generation, or per 2^step generations with --hashlife")
+	ruleStr := flags.String("rule", "", "rule to run under, overriding the one in the pattern file")
+	useHashLife := flags.Bool("hashlife", false, "use the HashLife engine")
+	stepLog := flags.Int("step", 0, "with --hashlife, move on 2^step generations at a time, so the statistics rows are that far apart")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *inFile == "" {
+		return errors.New("--in is required")
+	}
+	if *gens < 0 {
+		return errors.New("--gens can't be negative")
+	}
+
+	reader, err := os.Open(*inFile)
+	if err != nil {
+		return err
+	}
+	pattern, err := FindPatternReader(*inFile)(reader)
+	reader.Close()
+	if err != nil {
+		return err
+	}
+	pattern.Game.Filename = *inFile
+	pattern.Game.SetHistorySize(0)
+	if *ruleStr != "" {
+		pattern.Rule, err = ParseRule(*ruleStr)
+		if err != nil {
+			return err
+		}
+	}
+	if *useHashLife && pattern.Rule.IsGenerations() {
+		return errors.New("HashLife can't run Generations rules")
+	}
+
+	var stats *bufio.Writer
+	if *statsFile != "" {
+		file, err := os.Create(*statsFile)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		stats = bufio.NewWriter(file)
+		// the stride is how many generations on from the row before, which
+		// is more than one when HashLife steps more than one at a time
+		fmt.Fprintln(stats, "generation,stride,population,dying,min_x,min_y,max_x,max_y")
+	}
+	lastRow := pattern.Game.Generation
+	writeStats := func(population, dying int, minCell, maxCell golife.Cell) {
+		if stats == nil {
+			return
+		}
+		fmt.Fprintf(stats, "%d,%d,%d,%d,", pattern.Game.Generation, pattern.Game.Generation-lastRow, population, dying)
+		if population+dying == 0 {
+			fmt.Fprintln(stats, ",,,") // an empty pattern has no bounding box
+		} else {
+			fmt.Fprintf(stats, "%d,%d,%d,%d\n", minCell.X, minCell.Y, maxCell.X, maxCell.Y)
+		}
+		lastRow = pattern.Game.Generation
+	}
+
+	start := time.Now()
+	target := pattern.Game.Generation + *gens
+	if *useHashLife {
+		h := NewHashLife(pattern.Rule)
+		h.SetPopulation(pattern.Game.Population)
+		minCell, maxCell := h.BoundingBox()
+		writeStats(h.Size(), 0, minCell, maxCell)
+		for pattern.Game.Generation < target {
+			// don't overshoot the last generation
+			step := min(max(*stepLog, 0), maxHashLifeStepLog)
+			for step > 0 && pattern.Game.Generation+(1<<step) > target {
+				step--
+			}
+			h.Step(step)
+			pattern.Game.Generation += 1 << step
+			minCell, maxCell := h.BoundingBox()
+			writeStats(h.Size(), 0, minCell, maxCell)
+		}
+		pattern.Game.Population = h.Population()
+	} else {
+		minCell, maxCell := PatternBoundingBox(pattern)
+		writeStats(pattern.Game.Size(), len(pattern.Dying), minCell, maxCell)
+		for pattern.Game.Generation < target {
+			pattern.Dying = pattern.Rule.Next(pattern.Game, pattern.Dying)
+			minCell, maxCell := PatternBoundingBox(pattern)
+			writeStats(pattern.Game.Size(), len(pattern.Dying), minCell, maxCell)
+		}
+	}
+	elapsed := time.Since(start)
+
+	if stats != nil {
+		if err := stats.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if *outFile == "" {
+		err = WriteRLE(pattern, stdout)
+	} else {
+		var out *os.File
+		out, err = os.Create(*outFile)
+		if err != nil {
+			return err
+		}
+		err = FindPatternWriter(*outFile)(pattern, out)
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		fmt.Fprintf(stdout, "Ran %d generations in %v, %d live cells at generation %d\n",
+			*gens, elapsed, pattern.Game.Size(), pattern.Game.Generation)
+	}
+	return err
+}
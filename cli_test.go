@@ -0,0 +1,62 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunHeadlessStats(t *testing.T) {
+	dir := t.TempDir()
+	inFile := filepath.Join(dir, "pair.rle")
+	// two cells die out after one generation
+	if err := os.WriteFile(inFile, []byte("x = 2, y = 1\n2o!\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"standard", nil, []string{
+			"generation,stride,population,dying,min_x,min_y,max_x,max_y",
+			"0,0,2,0,0,0,1,0",
+			"1,1,0,0,,,,",
+			"2,1,0,0,,,,",
+			"3,1,0,0,,,,",
+			"4,1,0,0,,,,",
+		}},
+		{"hashlife", []string{"--hashlife"}, []string{
+			"generation,stride,population,dying,min_x,min_y,max_x,max_y",
+			"0,0,2,0,0,0,1,0",
+			"1,1,0,0,,,,",
+			"2,1,0,0,,,,",
+			"3,1,0,0,,,,",
+			"4,1,0,0,,,,",
+		}},
+		{"hashlife in steps", []string{"--hashlife", "--step", "1"}, []string{
+			"generation,stride,population,dying,min_x,min_y,max_x,max_y",
+			"0,0,2,0,0,0,1,0",
+			"2,2,0,0,,,,",
+			"4,2,0,0,,,,",
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			statsFile := filepath.Join(dir, test.name+".csv")
+			args := append([]string{"--gens", "4", "--in", inFile, "--stats", statsFile}, test.args...)
+			if err := runHeadless(args, io.Discard); err != nil {
+				t.Fatal(err)
+			}
+			contents, err := os.ReadFile(statsFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := strings.TrimSpace(string(contents)), strings.Join(test.want, "\n"); got != want {
+				t.Errorf("got stats\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
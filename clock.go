@@ -3,6 +3,8 @@ package main
 import (
 	"log"
 	"time"
+
+	"fyne.io/fyne/v2"
 )
 
 // This is a simple mechanism to make the animations of the running
@@ -31,10 +33,11 @@ func NewLifeSimClock(sim *LifeSim) *LifeSimClock {
 func (clk *LifeSimClock) doLifeTicks() {
 	for clk.Running {
 		<-clk.lifeTicker // Will block waiting for a clock tick
-		start := time.Now()
-		clk.life.Game.Next()
-		clk.life.LastStepTime = time.Since(start)
+		clk.life.Step()
 		clk.life.Dirty = true
+		if compare := clk.life.Compare; compare != nil {
+			compare.stepWith(clk.life)
+		}
 	}
 }
 
@@ -44,7 +47,10 @@ func (clk *LifeSimClock) LifeTick() {
 
 // The DisplayUpdateClock is designed to be a singleton, with only one instance
 // per running process.  It will cause only the selected tab to redraw it's contents
-// which should help when complex simlutations are not in front.
+// which should help when complex simlutations are not in front.  It also keeps
+// the tabs' running badges up to date, though not nearly as often.
+
+const runStateUpdateInterval = 100 * time.Millisecond
 
 type DisplayUpdateClock struct {
 	DisplayUpdateHz int
@@ -59,7 +65,12 @@ func StartDisplayUpdateClock(t *LifeTabs) *DisplayUpdateClock {
 }
 
 func (clk *DisplayUpdateClock) doDisplayRedraws() {
+	lastRunStateUpdate := time.Now()
 	for clk.Running {
+		if time.Since(lastRunStateUpdate) >= runStateUpdateInterval {
+			fyne.Do(clk.tabs.UpdateRunStates)
+			lastRunStateUpdate = time.Now()
+		}
 		lc := clk.tabs.CurrentLifeContainer()
 		if lc != nil {
 			func() {
@@ -69,6 +80,9 @@ func (clk *DisplayUpdateClock) doDisplayRedraws() {
 					}
 				}()
 				lc.Sim.Draw() // the Draw routine checks/clears the Dirty flag
+				if compare := lc.Sim.Compare; compare != nil {
+					compare.Draw()
+				}
 			}()
 		}
 		// This is last because the check for Running needs to happen immediately
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/pneumaticdeath/golife"
+
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Comparing shows a second sim beside the one in a tab, so two variants
+// of a pattern can be watched side by side.  The comparison doesn't get
+// controls of its own: it steps whenever the main sim steps, shows the
+// same part of the board, and panning or zooming it moves both.  It
+// can't be edited, but Swap Sides trades the two patterns so either one
+// can be worked on (or given a different rule).
+
+// newComparisonSim makes a sim that follows the leader
+func newComparisonSim(leader *LifeSim, pattern *Pattern) *LifeSim {
+	sim := NewLifeSim(func() {})
+	sim.leader = leader
+	sim.SetAutoZoom(false) // the leader zooms for both of them
+	sim.SetEditMode(false)
+	sim.loadComparison(pattern)
+	return sim
+}
+
+func (ls *LifeSim) loadComparison(pattern *Pattern) {
+	ls.Game = pattern.Game
+	ls.Game.SetHistorySize(Config.HistorySize())
+	ls.Rule = pattern.Rule
+	ls.Dying = pattern.Dying
+	ls.dyingHistory = nil
+	ls.hashLife = nil
+	ls.treeAhead = false
+	ls.Detector.Reset()
+	ls.Dirty = true
+}
+
+// stepWith steps the comparison the same way the leader just stepped
+func (ls *LifeSim) stepWith(leader *LifeSim) {
+	ls.StepLog = leader.StepLog
+	ls.SetHashLife(leader.hashLife != nil)
+	ls.Step()
+	ls.Dirty = true
+}
+
+// followLeader keeps the comparison showing the same part of the board,
+// in the same way, as the sim it's being compared with.  It's never
+// shown as editing since it can't be edited.
+func (ls *LifeSim) followLeader() {
+	leader := ls.leader
+	if leader == nil {
+		return
+	}
+	if ls.BoxDisplayMin != leader.BoxDisplayMin || ls.BoxDisplayMax != leader.BoxDisplayMax || ls.GlyphStyle != leader.GlyphStyle {
+		ls.BoxDisplayMin, ls.BoxDisplayMax = leader.BoxDisplayMin, leader.BoxDisplayMax
+		ls.GlyphStyle = leader.GlyphStyle
+		ls.Dirty = true
+	}
+	state := leader.GetState()
+	if state == simEditing {
+		state = simPaused
+	}
+	if state != ls.GetState() {
+		ls.SetState(state)
+		ls.Dirty = true
+	}
+}
+
+// viewBoundingBox returns the corners of the live cells, including the
+// ones in the comparison so zooming to fit shows both.
+func (ls *LifeSim) viewBoundingBox() (golife.Cell, golife.Cell) {
+	minCell, maxCell := ls.BoundingBox()
+	compare := ls.Compare
+	if compare == nil || compare.CellCount() == 0 {
+		return minCell, maxCell
+	}
+	compareMin, compareMax := compare.BoundingBox()
+	if ls.CellCount() == 0 {
+		return compareMin, compareMax
+	}
+	return golife.Cell{X: min(minCell.X, compareMin.X), Y: min(minCell.Y, compareMin.Y)},
+		golife.Cell{X: max(maxCell.X, compareMax.X), Y: max(maxCell.Y, compareMax.Y)}
+}
+
+// cellDiffers returns a test for live cells that aren't alive in the
+// other sim, or nil if differences aren't being highlighted.  Sims that
+// HashLife has run ahead of their populations can't be compared cell by
+// cell, so nothing is highlighted until they're paused.
+func (ls *LifeSim) cellDiffers() func(golife.Cell) bool {
+	other := ls.diffAgainst
+	if other == nil || ls.treeAhead || other.treeAhead {
+		return nil
+	}
+	population := other.Game.Population
+	return func(cell golife.Cell) bool {
+		return !population[cell]
+	}
+}
+
+// IsComparing returns whether there's a comparison beside the main sim
+func (lc *LifeContainer) IsComparing() bool {
+	return lc.Sim.Compare != nil
+}
+
+// StartCompare shows the pattern beside the main sim, replacing any
+// comparison that's already there.
+func (lc *LifeContainer) StartCompare(pattern *Pattern) {
+	lc.Control.StopSim()
+	defer lc.Control.UpdateBackButton() // the comparison has its own history
+	if lc.IsComparing() {
+		lc.Sim.Compare.loadComparison(pattern)
+		lc.Sim.ResizeToFit()
+		return
+	}
+	compare := newComparisonSim(lc.Sim, pattern)
+	lc.Sim.Compare = compare
+	scroll := container.NewScroll(compare)
+	scroll.Direction = container.ScrollNone
+	lc.simArea.Layout = layout.NewGridLayoutWithColumns(2)
+	lc.simArea.Objects = append(lc.simArea.Objects[:1], scroll)
+	lc.simArea.Refresh()
+	lc.Sim.ResizeToFit()
+}
+
+func (lc *LifeContainer) StopCompare() {
+	if !lc.IsComparing() {
+		return
+	}
+	lc.Control.StopSim()
+	lc.SetHighlightDiffs(false)
+	lc.Sim.Compare = nil
+	lc.simArea.Layout = layout.NewGridLayoutWithColumns(1)
+	lc.simArea.Objects = lc.simArea.Objects[:1]
+	lc.simArea.Refresh()
+	lc.Control.UpdateBackButton()
+	lc.Sim.Dirty = true
+}
+
+// SetHighlightDiffs turns on or off highlighting of the live cells that
+// are only alive on one side.
+func (lc *LifeContainer) SetHighlightDiffs(highlight bool) {
+	compare := lc.Sim.Compare
+	if compare == nil {
+		return
+	}
+	if highlight {
+		lc.Sim.diffAgainst, compare.diffAgainst = compare, lc.Sim
+	} else {
+		lc.Sim.diffAgainst, compare.diffAgainst = nil, nil
+	}
+	lc.Sim.Dirty, compare.Dirty = true, true
+}
+
+func (lc *LifeContainer) HighlightsDiffs() bool {
+	return lc.Sim.diffAgainst != nil
+}
+
+// SwapCompared trades the patterns on the two sides.  The main sim's
+// side can be undone like loading any other pattern.
+func (lc *LifeContainer) SwapCompared() {
+	compare := lc.Sim.Compare
+	if compare == nil {
+		return
+	}
+	lc.Control.StopSim()
+	theirs := compare.Pattern()
+	compare.loadComparison(lc.Sim.Pattern().Copy())
+	lc.SetPattern(theirs)
+}
+
+// ShowCompareTabDialog asks which of the other tabs to compare with,
+// calling compared once the comparison is showing.
+func ShowCompareTabDialog(tabs *LifeTabs, lc *LifeContainer, compared func()) {
+	names := make([]string, 0, len(tabs.DocTabs.Items))
+	others := make(map[string]*LifeContainer)
+	for index, item := range tabs.DocTabs.Items {
+		other, ok := item.Content.(*LifeContainer)
+		if !ok || other == lc {
+			continue
+		}
+		name := fmt.Sprintf("%d. %s", index+1, item.Text)
+		names = append(names, name)
+		others[name] = other
+	}
+	if len(names) == 0 {
+		dialog.ShowInformation("No other tabs", "Open the pattern to compare with in another tab first.", mainWindow)
+		return
+	}
+
+	tabSelector := widget.NewSelect(names, nil)
+	tabSelector.SetSelectedIndex(0)
+	formItems := []*widget.FormItem{widget.NewFormItem("Tab", tabSelector)}
+	dialog.ShowForm("Compare with tab", "Compare", "Cancel", formItems, func(confirmed bool) {
+		other := others[tabSelector.Selected]
+		if !confirmed || other == nil {
+			return
+		}
+		lc.StartCompare(other.Sim.Pattern().Copy())
+		compared()
+	}, mainWindow)
+}
@@ -16,6 +16,7 @@ type LifeContainer struct {
 	widget.BaseWidget
 
 	container *fyne.Container
+	simArea   *fyne.Container // the sim, with its comparison beside it when comparing
 
 	// The Sim element contains the basic logic of
 	// the simulation, and encapsulates the logic
@@ -33,6 +34,19 @@ type LifeContainer struct {
 	// The Status object is responisble for providing
 	// the user with information about the simulation.
 	Status *StatusBar
+
+	// The Chart plots the history of the simulation,
+	// and lives in a collapsible panel above the Status.
+	Chart *ChartPanel
+
+	// The Stamps palette lists objects that can be
+	// dropped into the game, in a collapsible panel
+	// beside the Sim.
+	Stamps *StampPalette
+
+	// Bookmarks are snapshots of generations that
+	// can be gone back to, however long ago they were.
+	Bookmarks []*Bookmark
 }
 
 func NewLifeContainer(menuUpdateCallback func()) *LifeContainer {
@@ -41,10 +55,15 @@ func NewLifeContainer(menuUpdateCallback func()) *LifeContainer {
 	lc.Sim = NewLifeSim(menuUpdateCallback)
 	lc.Control = NewControlBar(lc.Sim)
 	lc.Status = NewStatusBar(lc.Sim, lc.Control)
+	lc.Chart = NewChartPanel(lc.Sim)
+	lc.Stamps = NewStampPalette(lc.Sim)
 
 	scroll := container.NewScroll(lc.Sim)
 	scroll.Direction = container.ScrollNone
-	lc.container = container.NewBorder(lc.Control, lc.Status, nil, nil, scroll)
+	chartAccordion := widget.NewAccordion(widget.NewAccordionItem("Chart", lc.Chart))
+	stampsAccordion := widget.NewAccordion(widget.NewAccordionItem("Stamps", lc.Stamps))
+	lc.simArea = container.NewGridWithColumns(1, scroll)
+	lc.container = container.NewBorder(lc.Control, container.NewVBox(chartAccordion, lc.Status), nil, stampsAccordion, lc.simArea)
 
 	lc.ExtendBaseWidget(lc)
 	return lc
@@ -54,17 +73,82 @@ func (lc *LifeContainer) CreateRenderer() fyne.WidgetRenderer {
 	return widget.NewSimpleRenderer(lc.container)
 }
 
+// SetGame replaces the game, keeping the current rule
 func (lc *LifeContainer) SetGame(game *golife.Game) {
+	lc.SetPattern(&Pattern{Game: game, Rule: lc.Sim.Rule})
+}
+
+// SetPattern replaces the game along with its rule and dying cells
+func (lc *LifeContainer) SetPattern(pattern *Pattern) {
+	lc.Sim.RecordEdit(Edit{Before: lc.Sim.Pattern(), After: pattern})
+	lc.replacePattern(pattern)
+	lc.Sim.EditMode.Set(pattern.Game.Size() == 0)
+}
+
+// SetRule changes the rule the sim runs under
+func (lc *LifeContainer) SetRule(rule Rule) {
+	lc.Sim.SyncPopulation()
+	lc.Sim.Rule = rule
+	if !rule.IsGenerations() {
+		lc.Sim.Dying = nil
+	}
+	lc.Sim.dyingHistory = nil
+	lc.Sim.Detector.Reset()
+	lc.Control.RuleChanged()
+	lc.Sim.Dirty = true
+}
+
+func (lc *LifeContainer) replacePattern(pattern *Pattern) {
 	lc.Control.StopSim()
-	lc.Sim.Game = game
+	lc.Sim.ClearSelection()
+	lc.Sim.CancelPaste()
+	lc.Sim.Game = pattern.Game
+	lc.Sim.newLineage()
+	lc.Sim.treeAhead = false
 	lc.Sim.Game.SetHistorySize(Config.HistorySize())
+	lc.SetRule(pattern.Rule)
+	lc.Sim.Dying = pattern.Dying
+	lc.Control.UpdateBackButton()
 	lc.Sim.ResizeToFit()
-	lc.Sim.EditMode.Set(game.Size() == 0)
+	lc.Sim.Dirty = true
+}
+
+// Undo reverses the last edit made in this container, returning false if
+// there was nothing that could be undone.
+func (lc *LifeContainer) Undo() bool {
+	lc.Control.StopSim()
+	pattern := lc.Sim.Pattern()
+	ok := lc.Sim.Edits.Undo(pattern)
+	lc.afterUndoRedo(pattern)
+	return ok
+}
+
+// Redo reapplies the last edit that was undone.
+func (lc *LifeContainer) Redo() bool {
+	lc.Control.StopSim()
+	pattern := lc.Sim.Pattern()
+	ok := lc.Sim.Edits.Redo(pattern)
+	lc.afterUndoRedo(pattern)
+	return ok
+}
+
+func (lc *LifeContainer) afterUndoRedo(pattern *Pattern) {
+	lc.Sim.Detector.Reset()
+	lc.Sim.newLineage()
+	if pattern.Game != lc.Sim.Game {
+		lc.replacePattern(pattern)
+	} else {
+		lc.Sim.Dying = pattern.Dying
+		if lc.Sim.IsAutoZoom() {
+			lc.Sim.ResizeToFit()
+		}
+	}
 	lc.Sim.Dirty = true
 }
 
 func (lc *LifeContainer) StopClocks() {
 	lc.Status.StopClocks()
+	lc.Chart.StopClocks()
 	lc.Control.StopClocks()
 	lc.Sim.StopClocks()
 }
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"math"
+	"sync/atomic"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -37,13 +38,34 @@ type ControlBar struct {
 	forwardStepButton  *widget.Button
 	zoomOutButton      *widget.Button
 	zoomInButton       *widget.Button
+	recordButton       *widget.Button
 	glyphSelector      *widget.Select
+	paintSelector      *widget.Select // which state a tap paints, only shown for Generations rules
+	toolSelector       *widget.Select // what a drag does in edit mode
+	brushSizeSelector  *widget.Select
+	brushShapeSelector *widget.Select
+	engineSelector     *widget.Select // standard engine or HashLife
+	stepSelector       *widget.Select // generations per step with HashLife
 	stateDisplay       *widget.Label
 	speedSlider        *widget.Slider
 	bar                *fyne.Container
 	running            bool
+	keepRunning        atomic.Bool // keep running while the tab isn't selected
+	runningAll         atomic.Bool // started by Run All, so keep running until it's stopped
+	hidden             atomic.Bool // the tab isn't selected
 }
 
+// How a tab's sim is running, shown as a badge on the tab
+type RunState int
+
+const (
+	runStopped RunState = iota
+	runActive
+	runHeld // running, but waiting for its tab to be selected again
+)
+
+const heldCheckInterval = 100 * time.Millisecond
+
 func (controlBar *ControlBar) IsRunning() bool {
 	return controlBar.running
 }
@@ -60,9 +82,7 @@ func NewControlBar(sim *LifeSim) *ControlBar {
 	controlBar.backwardStepButton = widget.NewButtonWithIcon("", theme.MediaSkipPreviousIcon(), func() {
 		controlBar.StepBackward()
 	})
-	if len(controlBar.life.Game.History) == 0 {
-		controlBar.backwardStepButton.Disable()
-	}
+	controlBar.UpdateBackButton()
 
 	controlBar.runStopButton = widget.NewButtonWithIcon("", theme.MediaPlayIcon(), func() {
 		if controlBar.IsRunning() {
@@ -73,23 +93,82 @@ func NewControlBar(sim *LifeSim) *ControlBar {
 	})
 
 	controlBar.forwardStepButton = widget.NewButtonWithIcon("", theme.MediaSkipNextIcon(), func() {
-		if controlBar.IsRunning() {
-			controlBar.StopSim() // If we're running, we've probably already calculated the next step
-		} else {
-			controlBar.StepForward()
-		}
+		controlBar.StepOrStop()
 	})
 
 	controlBar.zoomOutButton = widget.NewButtonWithIcon("", theme.ZoomOutIcon(), func() { controlBar.ZoomOut() })
 
 	controlBar.zoomInButton = widget.NewButtonWithIcon("", theme.ZoomInIcon(), func() { controlBar.ZoomIn() })
 
+	controlBar.recordButton = widget.NewButtonWithIcon("", theme.MediaRecordIcon(), func() {
+		controlBar.StopSim()
+		ShowRecordDialog(controlBar.life)
+	})
+
 	controlBar.glyphSelector = widget.NewSelect([]string{"Rectangle", "RoundedRectangle", "Circle"}, func(selection string) {
 		controlBar.life.GlyphStyle = selection
 		controlBar.life.Dirty = true
 	})
 	controlBar.glyphSelector.SetSelected(controlBar.life.GlyphStyle)
 
+	controlBar.paintSelector = widget.NewSelect([]string{}, func(selection string) {
+		for state := 1; state < controlBar.life.Rule.States; state++ {
+			if paintStateName(uint8(state)) == selection {
+				controlBar.life.PaintState = uint8(state)
+			}
+		}
+	})
+
+	controlBar.toolSelector = widget.NewSelect(editTools, func(selection string) {
+		controlBar.life.EditTool = selection
+		controlBar.updateToolSelectors()
+	})
+	controlBar.toolSelector.SetSelected(controlBar.life.EditTool)
+
+	brushSizeOptions := make([]string, 0, len(brushSizes))
+	for _, size := range brushSizes {
+		brushSizeOptions = append(brushSizeOptions, brushSizeName(size))
+	}
+	controlBar.brushSizeSelector = widget.NewSelect(brushSizeOptions, func(selection string) {
+		for _, size := range brushSizes {
+			if brushSizeName(size) == selection {
+				Config.SetBrushSize(size)
+			}
+		}
+	})
+	controlBar.brushSizeSelector.SetSelected(brushSizeName(Config.BrushSize()))
+
+	controlBar.brushShapeSelector = widget.NewSelect([]string{brushSquare, brushCircle}, func(selection string) {
+		Config.SetBrushShape(selection)
+	})
+	controlBar.brushShapeSelector.SetSelected(Config.BrushShape())
+	controlBar.updateToolSelectors()
+
+	controlBar.engineSelector = widget.NewSelect([]string{engineStandard, engineHashLife}, func(selection string) {
+		controlBar.life.SetHashLife(selection == engineHashLife)
+		if controlBar.life.UsingHashLife() {
+			controlBar.stepSelector.Show()
+		} else {
+			controlBar.stepSelector.Hide()
+		}
+	})
+
+	stepOptions := make([]string, 0, maxHashLifeStepLog+1)
+	for stepLog := 0; stepLog <= maxHashLifeStepLog; stepLog++ {
+		stepOptions = append(stepOptions, stepSizeName(stepLog))
+	}
+	controlBar.stepSelector = widget.NewSelect(stepOptions, func(selection string) {
+		for stepLog := 0; stepLog <= maxHashLifeStepLog; stepLog++ {
+			if stepSizeName(stepLog) == selection {
+				controlBar.life.StepLog = stepLog
+			}
+		}
+	})
+	controlBar.stepSelector.SetSelected(stepSizeName(controlBar.life.StepLog))
+	controlBar.engineSelector.SetSelected(engineStandard)
+
+	controlBar.RuleChanged()
+
 	controlBar.stateDisplay = widget.NewLabel(controlBar.life.StateLabel())
 	controlBar.stateDisplay.Alignment = fyne.TextAlignCenter
 	controlBar.life.State.AddListener(binding.NewDataListener(func() {
@@ -112,22 +191,17 @@ func NewControlBar(sim *LifeSim) *ControlBar {
 	controlBar.speedSlider.SetValue(defaultSpeed)
 	controlBar.speedSlider.Step = 0.1
 
-	fasterButton := widget.NewButton("faster", func() {
-		newVal := controlBar.speedSlider.Value - 0.1
-		controlBar.speedSlider.SetValue(max(maxSpeed, newVal))
-	})
+	fasterButton := widget.NewButton("faster", func() { controlBar.Faster() })
 	fasterButton.Alignment = widget.ButtonAlignTrailing
 
-	slowerButton := widget.NewButton("slower", func() {
-		newVal := controlBar.speedSlider.Value + 0.1
-		controlBar.speedSlider.SetValue(min(minSpeed, newVal))
-	})
+	slowerButton := widget.NewButton("slower", func() { controlBar.Slower() })
 	slowerButton.Alignment = widget.ButtonAlignLeading
 
 	controlBar.bar = container.New(layout.NewAdaptiveGridLayout(2),
 		container.New(layout.NewHBoxLayout(), controlBar.backwardStepButton, controlBar.runStopButton,
-			controlBar.forwardStepButton, controlBar.zoomOutButton, controlBar.zoomInButton,
-			controlBar.glyphSelector, layout.NewSpacer(), controlBar.stateDisplay, layout.NewSpacer()),
+			controlBar.forwardStepButton, controlBar.zoomOutButton, controlBar.zoomInButton, controlBar.recordButton,
+			controlBar.glyphSelector, controlBar.paintSelector, controlBar.toolSelector, controlBar.brushSizeSelector,
+			controlBar.brushShapeSelector, controlBar.engineSelector, controlBar.stepSelector, layout.NewSpacer(), controlBar.stateDisplay, layout.NewSpacer()),
 		// container.New(xlayout.NewHPortion([]float64{0.2, 0.6, 0.2}), fasterButton, controlBar.speedSlider, slowerButton))
 		container.NewBorder(nil, nil, fasterButton, slowerButton, controlBar.speedSlider))
 
@@ -137,6 +211,7 @@ func NewControlBar(sim *LifeSim) *ControlBar {
 	controlBar.life.EditMode.AddListener(binding.NewDataListener(func() {
 		if controlBar.life.IsEditable() {
 			controlBar.StopSim()
+			controlBar.life.SyncPopulation() // editing works on the population map, not the HashLife tree
 			if controlBar.life.Scale > 0.0 && controlBar.life.Scale < 4.0 {
 				confirm := dialog.NewConfirm("Scale is very small",
 					"Each cell is very small on the screen.  Would you like to zoom in?",
@@ -152,11 +227,17 @@ func NewControlBar(sim *LifeSim) *ControlBar {
 				confirm.Show()
 			}
 			controlBar.life.SetState(simEditing)
-		} else if controlBar.IsRunning() {
-			controlBar.life.SetState(simRunning)
 		} else {
-			controlBar.life.SetState(simPaused)
+			// selections and pastes only live in edit mode
+			controlBar.life.ClearSelection()
+			controlBar.life.CancelPaste()
+			if controlBar.IsRunning() {
+				controlBar.life.SetState(simRunning)
+			} else {
+				controlBar.life.SetState(simPaused)
+			}
 		}
+		controlBar.updateToolSelectors()
 		controlBar.life.Dirty = true
 	}))
 
@@ -165,12 +246,47 @@ func NewControlBar(sim *LifeSim) *ControlBar {
 }
 
 func (controlBar *ControlBar) StopSim() {
+	controlBar.runningAll.Store(false)
 	if controlBar.IsRunning() {
 		controlBar.running = false
 		controlBar.setRunStopIcon(theme.MediaPlayIcon())
 	}
 }
 
+func (controlBar *ControlBar) SetKeepRunning(keep bool) {
+	controlBar.keepRunning.Store(keep)
+}
+
+func (controlBar *ControlBar) KeepRunning() bool {
+	return controlBar.keepRunning.Load()
+}
+
+// RunAll starts the sim and keeps it running while hidden, until it's
+// next stopped, without changing the keep running setting
+func (controlBar *ControlBar) RunAll() {
+	controlBar.StartSim()
+	controlBar.runningAll.Store(true)
+}
+
+// SetHidden is called when the tab is deselected or selected again
+func (controlBar *ControlBar) SetHidden(hidden bool) {
+	controlBar.hidden.Store(hidden)
+}
+
+func (controlBar *ControlBar) IsHeld() bool {
+	return controlBar.hidden.Load() && !controlBar.keepRunning.Load() && !controlBar.runningAll.Load()
+}
+
+func (controlBar *ControlBar) RunState() RunState {
+	switch {
+	case !controlBar.IsRunning():
+		return runStopped
+	case controlBar.IsHeld():
+		return runHeld
+	}
+	return runActive
+}
+
 func (controlBar *ControlBar) StartSim() {
 	if !controlBar.IsRunning() {
 		controlBar.setRunStopIcon(theme.MediaPauseIcon())
@@ -199,6 +315,17 @@ func (controlBar *ControlBar) ZoomOut() {
 	controlBar.life.Dirty = true
 }
 
+// The slider is a log scale of the delay, so faster is to the left
+func (controlBar *ControlBar) Faster() {
+	newVal := controlBar.speedSlider.Value - controlBar.speedSlider.Step
+	controlBar.speedSlider.SetValue(max(controlBar.speedSlider.Min, newVal))
+}
+
+func (controlBar *ControlBar) Slower() {
+	newVal := controlBar.speedSlider.Value + controlBar.speedSlider.Step
+	controlBar.speedSlider.SetValue(min(controlBar.speedSlider.Max, newVal))
+}
+
 func (controlBar *ControlBar) setRunStopIcon(icon fyne.Resource) {
 	fyne.Do(func() {
 		controlBar.runStopButton.SetIcon(icon)
@@ -211,9 +338,18 @@ func (controlBar *ControlBar) CreateRenderer() fyne.WidgetRenderer {
 
 func (controlBar *ControlBar) RunGame() {
 	controlBar.life.SetState(simRunning)
+	controlBar.life.JustSettled() // only stop for patterns that settle while we're running
 	for controlBar.IsRunning() {
+		if controlBar.IsHeld() {
+			time.Sleep(heldCheckInterval)
+			continue
+		}
 		controlBar.StepForward()
-		if controlBar.life.Game.Size() == 0 {
+		if controlBar.life.JustSettled() && Config.PauseWhenSettled() {
+			controlBar.StopSim()
+			break
+		}
+		if controlBar.life.CellCount() == 0 && len(controlBar.life.Dying) == 0 {
 			controlBar.StopSim()
 			break
 		}
@@ -232,23 +368,132 @@ func (controlBar *ControlBar) StepForward() {
 	controlBar.updateCadence = time.Since(controlBar.lastUpdateTime)
 	controlBar.lastUpdateTime = time.Now()
 	controlBar.Clock.LifeTick()
-	if len(controlBar.life.Game.History) > 0 {
-		fyne.Do(controlBar.backwardStepButton.Enable) // We might have history now
-	}
+	fyne.Do(controlBar.UpdateBackButton) // We might have history now
 }
 
-func (controlBar *ControlBar) StepBackward() {
+// StepOrStop steps forward, unless we're running, in which case we've
+// probably already calculated the next step and just stop there.
+func (controlBar *ControlBar) StepOrStop() {
 	if controlBar.IsRunning() {
 		controlBar.StopSim()
+	} else {
+		controlBar.StepForward()
 	}
-	err := controlBar.life.Game.Previous()
-	if err != nil {
-		fmt.Println("Got error trying to step backwards", err)
+}
+
+// StepsBack returns how many generations the sim can step back, which
+// is only as far as the comparison can too, to keep them together
+func (controlBar *ControlBar) StepsBack() int {
+	steps := len(controlBar.life.Game.History)
+	if compare := controlBar.life.Compare; compare != nil {
+		steps = min(steps, len(compare.Game.History))
 	}
-	if len(controlBar.life.Game.History) == 0 {
+	return steps
+}
+
+// UpdateBackButton enables the step back button when there's a step to
+// go back to.  It's called whenever the generation changes, so it also
+// has the menus updated, since undo only works at the generation the
+// edit was made in.
+func (controlBar *ControlBar) UpdateBackButton() {
+	if controlBar.StepsBack() > 0 {
+		controlBar.backwardStepButton.Enable()
+	} else {
 		controlBar.backwardStepButton.Disable()
 	}
+	controlBar.life.menuUpdate()
+}
+
+// StepBackward steps the sim and any comparison back a generation,
+// returning false if they couldn't both go back.
+func (controlBar *ControlBar) StepBackward() bool {
+	if controlBar.IsRunning() {
+		controlBar.StopSim()
+	}
+	defer controlBar.UpdateBackButton()
+	if controlBar.StepsBack() == 0 {
+		return false
+	}
+	if err := controlBar.life.Previous(); err != nil {
+		fmt.Println("Got error trying to step backwards", err)
+		return false
+	}
 	controlBar.life.Dirty = true
+	if compare := controlBar.life.Compare; compare != nil {
+		if err := compare.Previous(); err != nil {
+			fmt.Println("Got error trying to step the comparison backwards", err)
+		}
+		compare.Dirty = true
+	}
+	return true
+}
+
+// updateToolSelectors shows the edit tools only in edit mode, and the
+// brush settings only when there's a brush to set.
+func (controlBar *ControlBar) updateToolSelectors() {
+	if controlBar.brushShapeSelector == nil {
+		return // still being built
+	}
+	editing := controlBar.life.IsEditable()
+	if editing {
+		controlBar.toolSelector.Show()
+	} else {
+		controlBar.toolSelector.Hide()
+	}
+	if editing && controlBar.life.usingBrush() {
+		controlBar.brushSizeSelector.Show()
+		controlBar.brushShapeSelector.Show()
+	} else {
+		controlBar.brushSizeSelector.Hide()
+		controlBar.brushShapeSelector.Hide()
+	}
+}
+
+const (
+	engineStandard = "Standard"
+	engineHashLife = "HashLife"
+)
+
+func stepSizeName(stepLog int) string {
+	if stepLog == 0 {
+		return "Step 1"
+	}
+	return fmt.Sprintf("Step 2^%d", stepLog)
+}
+
+func paintStateName(state uint8) string {
+	if state == 1 {
+		return "Alive"
+	}
+	return fmt.Sprintf("Dying %d", state-1)
+}
+
+// RuleChanged updates the controls that depend on the rule.  The paint
+// state selector is only useful for Generations rules.
+func (controlBar *ControlBar) RuleChanged() {
+	rule := controlBar.life.Rule
+	if int(controlBar.life.PaintState) >= rule.States {
+		controlBar.life.PaintState = 1
+	}
+	options := make([]string, 0, rule.States)
+	for state := 1; state < rule.States; state++ {
+		options = append(options, paintStateName(uint8(state)))
+	}
+	controlBar.paintSelector.SetOptions(options)
+	controlBar.paintSelector.SetSelected(paintStateName(controlBar.life.PaintState))
+	if rule.IsGenerations() {
+		controlBar.paintSelector.Show()
+		// HashLife only handles two state rules
+		controlBar.engineSelector.Disable()
+	} else {
+		controlBar.paintSelector.Hide()
+		controlBar.engineSelector.Enable()
+	}
+	if controlBar.life.UsingHashLife() {
+		controlBar.stepSelector.Show()
+	} else {
+		controlBar.stepSelector.Hide()
+	}
 }
 
 func (cb *ControlBar) StopClocks() {
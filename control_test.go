@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+)
+
+func TestRunAllKeepsRunningUntilStopped(t *testing.T) {
+	InitConfig(test.NewApp())
+	lc := NewLifeContainer(func() {})
+	control := lc.Control
+	control.SetHidden(true)
+	if !control.IsHeld() {
+		t.Fatal("hidden tab isn't held")
+	}
+
+	control.RunAll()
+	if control.IsHeld() {
+		t.Error("tab started by Run All is held while hidden")
+	}
+	if control.KeepRunning() {
+		t.Error("Run All changed the keep running setting")
+	}
+
+	control.StopSim()
+	if !control.IsHeld() {
+		t.Error("tab still kept running after being paused")
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/pneumaticdeath/golife"
+)
+
+// The PeriodDetector watches the generations go by and notices when the
+// pattern starts repeating.  Each generation is boiled down to a hash of
+// its cells relative to the upper left corner of its bounding box, so a
+// spaceship hashes the same wherever it has flown to, and we remember the
+// generation and corner that each hash was last seen at.  When a hash
+// comes back around, the difference in generations is the period and the
+// difference in corners is how far it moved.  Different patterns can
+// hash the same, so the generations themselves are kept too, and a repeat
+// only counts once the cells have been checked.
+
+const (
+	detectorMaxHistory = 1000    // generations remembered, which is the longest period we can find
+	detectorMaxCells   = 2000000 // cells kept over all the generations remembered, which can cut the history short
+)
+
+type PeriodKind int
+
+const (
+	periodUnknown PeriodKind = iota
+	periodDead
+	periodStillLife
+	periodOscillator
+	periodSpaceship
+)
+
+type Periodicity struct {
+	Kind       PeriodKind
+	Period     int
+	DX, DY     golife.Coord // how far a spaceship moves each period
+	Generation int          // the generation it was detected at
+}
+
+func (p Periodicity) String() string {
+	switch p.Kind {
+	case periodDead:
+		return "died out"
+	case periodStillLife:
+		return "still life"
+	case periodOscillator:
+		return fmt.Sprintf("oscillator, period %d", p.Period)
+	case periodSpaceship:
+		return fmt.Sprintf("spaceship, period %d, displacement (%d,%d)", p.Period, p.DX, p.DY)
+	}
+	return "-"
+}
+
+type seenAt struct {
+	generation int
+	corner     golife.Cell
+	pop        golife.Population
+	dying      DyingCells
+}
+
+func (seen seenAt) cells() int {
+	return len(seen.pop) + len(seen.dying)
+}
+
+// matches checks cell by cell that the generation is the one seen before,
+// moved by the offset
+func (seen seenAt) matches(pop golife.Population, dying DyingCells, offset golife.Cell) bool {
+	if len(pop) != len(seen.pop) || len(dying) != len(seen.dying) {
+		return false
+	}
+	for cell := range pop {
+		if !seen.pop[golife.Cell{X: cell.X - offset.X, Y: cell.Y - offset.Y}] {
+			return false
+		}
+	}
+	for cell, state := range dying {
+		if seen.dying[golife.Cell{X: cell.X - offset.X, Y: cell.Y - offset.Y}] != state {
+			return false
+		}
+	}
+	return true
+}
+
+type PeriodDetector struct {
+	seen           map[uint64]seenAt
+	order          []uint64 // hashes of consecutive generations in the order they were seen, for forgetting old ones
+	cells          int      // cells kept in seen
+	lastGeneration int
+	Result         Periodicity
+}
+
+func NewPeriodDetector() *PeriodDetector {
+	d := &PeriodDetector{}
+	d.Reset()
+	return d
+}
+
+// Reset forgets everything, for when the pattern has been changed by
+// something other than stepping forward.
+func (d *PeriodDetector) Reset() {
+	d.seen = make(map[uint64]seenAt)
+	d.order = d.order[:0]
+	d.cells = 0
+	d.lastGeneration = -1
+	d.Result = Periodicity{}
+}
+
+// mixCell scrambles a cell's offset and state into 64 bits.  This is the
+// splitmix64 finalizer, which is plenty good enough for a hash table.
+func mixCell(x, y golife.Coord, state uint8) uint64 {
+	h := uint64(x)*0x9e3779b97f4a7c15 ^ uint64(y)*0xc2b2ae3d27d4eb4f ^ uint64(state)<<56
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
+
+// hashPattern returns a hash of the pattern normalized for translation,
+// along with the corner it was normalized to.  The cell hashes are added
+// up so the order we visit the cells in doesn't matter.
+func hashPattern(pop golife.Population, dying DyingCells) (uint64, golife.Cell) {
+	corner, _ := PatternBoundingBox(&Pattern{Game: &golife.Game{Population: pop}, Dying: dying})
+	hash := uint64(len(pop))
+	for cell := range pop {
+		hash += mixCell(cell.X-corner.X, cell.Y-corner.Y, 1)
+	}
+	for cell, state := range dying {
+		hash += mixCell(cell.X-corner.X, cell.Y-corner.Y, state)
+	}
+	return hash, corner
+}
+
+// Observe looks at the next generation.  It returns true if this is the
+// generation the pattern was found to repeat.  The population and dying
+// cells are kept, so they mustn't be changed afterwards.
+func (d *PeriodDetector) Observe(generation int, pop golife.Population, dying DyingCells) bool {
+	if generation != d.lastGeneration+1 {
+		d.Reset()
+	}
+	d.lastGeneration = generation
+	if d.Result.Kind != periodUnknown {
+		return false
+	}
+
+	if len(pop) == 0 && len(dying) == 0 {
+		d.Result = Periodicity{Kind: periodDead, Generation: generation}
+		return true
+	}
+
+	hash, corner := hashPattern(pop, dying)
+	prev, found := d.seen[hash]
+	offset := golife.Cell{X: corner.X - prev.corner.X, Y: corner.Y - prev.corner.Y}
+	if found && prev.matches(pop, dying, offset) {
+		result := Periodicity{Period: generation - prev.generation, Generation: generation, DX: offset.X, DY: offset.Y}
+		switch {
+		case result.DX != 0 || result.DY != 0:
+			result.Kind = periodSpaceship
+		case result.Period == 1:
+			result.Kind = periodStillLife
+		default:
+			result.Kind = periodOscillator
+		}
+		d.Result = result
+		return true
+	}
+
+	if found {
+		d.cells -= prev.cells() // a different pattern with the same hash
+	}
+	seen := seenAt{generation: generation, corner: corner, pop: pop, dying: dying}
+	d.seen[hash] = seen
+	d.cells += seen.cells()
+	d.order = append(d.order, hash)
+	for len(d.order) > 1 && (len(d.order) > detectorMaxHistory || d.cells > detectorMaxCells) {
+		oldest := generation - len(d.order) + 1
+		if old := d.seen[d.order[0]]; old.generation == oldest {
+			d.cells -= old.cells()
+			delete(d.seen, d.order[0])
+		}
+		d.order = d.order[1:]
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/pneumaticdeath/golife"
+)
+
+// runDetector steps the cells under Conway's rule until the detector
+// finds a repeat, returning what it found
+func runDetector(t *testing.T, cells []golife.Cell, maxGenerations int) Periodicity {
+	d := NewPeriodDetector()
+	pop := make(golife.Population)
+	for _, cell := range cells {
+		pop[cell] = true
+	}
+	for generation := range maxGenerations {
+		if d.Observe(generation, pop, nil) {
+			return d.Result
+		}
+		pop = ConwayRule.Step(pop)
+	}
+	t.Fatalf("nothing found in %d generations", maxGenerations)
+	return Periodicity{}
+}
+
+func TestPeriodDetector(t *testing.T) {
+	tests := []struct {
+		name  string
+		cells []golife.Cell
+		want  Periodicity
+	}{
+		{"block", []golife.Cell{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}},
+			Periodicity{Kind: periodStillLife, Period: 1, Generation: 1}},
+		{"blinker", testBlinker, Periodicity{Kind: periodOscillator, Period: 2, Generation: 2}},
+		{"glider", testGlider, Periodicity{Kind: periodSpaceship, Period: 4, DX: 1, DY: 1, Generation: 4}},
+		{"pair", []golife.Cell{{X: 0, Y: 0}, {X: 1, Y: 0}}, Periodicity{Kind: periodDead, Generation: 1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := runDetector(t, test.cells, 2000); got != test.want {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPeriodDetectorResets(t *testing.T) {
+	d := NewPeriodDetector()
+	block := golife.Population{{X: 0, Y: 0}: true, {X: 1, Y: 0}: true, {X: 0, Y: 1}: true, {X: 1, Y: 1}: true}
+	d.Observe(0, block, nil)
+	if d.Observe(5, block, nil) {
+		t.Error("found a repeat across generations that weren't consecutive")
+	}
+	if !d.Observe(6, block, nil) || d.Result.Period != 1 {
+		t.Errorf("got %+v after starting again, want a still life", d.Result)
+	}
+}
+
+func TestPeriodDetectorChecksCells(t *testing.T) {
+	d := NewPeriodDetector()
+	pop := golife.Population{{X: 0, Y: 0}: true, {X: 2, Y: 0}: true}
+	hash, corner := hashPattern(pop, nil)
+	// pretend a different pattern had the same hash the generation before
+	other := golife.Population{{X: 0, Y: 0}: true, {X: 1, Y: 0}: true}
+	d.Observe(0, other, nil)
+	d.seen = map[uint64]seenAt{hash: {generation: 0, corner: corner, pop: other}}
+	if d.Observe(1, pop, nil) {
+		t.Errorf("reported %+v from a hash that matched different cells", d.Result)
+	}
+	if !d.Observe(2, pop, nil) || d.Result.Period != 1 {
+		t.Errorf("got %+v, want the real repeat", d.Result)
+	}
+}
+
+func TestPeriodDetectorGenerations(t *testing.T) {
+	d := NewPeriodDetector()
+	pop := golife.Population{{X: 0, Y: 0}: true}
+	d.Observe(0, pop, DyingCells{{X: 1, Y: 0}: 2})
+	if d.Observe(1, pop, DyingCells{{X: 1, Y: 0}: 3}) {
+		t.Error("cells in different dying states counted as a repeat")
+	}
+	if !d.Observe(2, pop, DyingCells{{X: 1, Y: 0}: 3}) {
+		t.Error("the same dying cells didn't count as a repeat")
+	}
+}
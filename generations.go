@@ -0,0 +1,91 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/pneumaticdeath/golife"
+)
+
+// Support for the multi-state "Generations" rules.  The live cells (state
+// 1) stay in the golife.Game population so everything that only cares
+// about live cells keeps working, and the cells that are dying (states 2
+// and up) are kept alongside it in the LifeSim.
+
+// DyingCells maps each dying cell to its state
+type DyingCells map[golife.Cell]uint8
+
+func (dying DyingCells) Copy() DyingCells {
+	if dying == nil {
+		return nil
+	}
+	newDying := make(DyingCells, len(dying))
+	for cell, state := range dying {
+		newDying[cell] = state
+	}
+	return newDying
+}
+
+// CellState returns the state of the cell: 0 for dead, 1 for alive and 2
+// and up for dying.
+func (ls *LifeSim) CellState(cell golife.Cell) uint8 {
+	if ls.Game.HasCell(cell) {
+		return 1
+	}
+	return ls.Dying[cell]
+}
+
+// SetCellState changes the state of a single cell, keeping the live
+// population and the dying cells consistent.
+func (ls *LifeSim) SetCellState(cell golife.Cell, state uint8) {
+	switch state {
+	case 0:
+		ls.Game.RemoveCell(cell)
+		delete(ls.Dying, cell)
+	case 1:
+		ls.Game.AddCell(cell)
+		delete(ls.Dying, cell)
+	default:
+		ls.Game.RemoveCell(cell)
+		if ls.Dying == nil {
+			ls.Dying = make(DyingCells)
+		}
+		ls.Dying[cell] = state
+	}
+}
+
+// Previous steps the game back a generation, along with its dying cells
+func (ls *LifeSim) Previous() error {
+	err := ls.Game.Previous()
+	if err != nil {
+		return err
+	}
+	if len(ls.dyingHistory) > 0 {
+		ls.Dying = ls.dyingHistory[len(ls.dyingHistory)-1]
+		ls.dyingHistory = ls.dyingHistory[:len(ls.dyingHistory)-1]
+	} else {
+		ls.Dying = nil
+	}
+	return nil
+}
+
+// StateColor returns the color to draw a cell in the given state.  Live
+// cells get the usual color for the mode the sim is in, and the dying
+// states fade from there towards the background color.
+func (ls *LifeSim) StateColor(state uint8) color.Color {
+	cellColor := ls.ModeColor()
+	if state <= 1 || ls.Rule.States <= 2 {
+		return cellColor
+	}
+	// the last dying state should still be visible against the background
+	fade := float64(state-1) / float64(ls.Rule.States)
+	return blendColors(cellColor, Config.BackgroundColor(), fade)
+}
+
+func blendColors(from, to color.Color, fraction float64) color.Color {
+	f := color.NRGBAModel.Convert(from).(color.NRGBA)
+	t := color.NRGBAModel.Convert(to).(color.NRGBA)
+	blend := func(a, b uint8) uint8 {
+		return uint8(float64(a)*(1.0-fraction) + float64(b)*fraction)
+	}
+	return color.NRGBA{R: blend(f.R, t.R), G: blend(f.G, t.G), B: blend(f.B, t.B), A: blend(f.A, t.A)}
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+
+	"github.com/pneumaticdeath/golife"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/theme"
+)
+
+// The grid draws lines between the cells, with a major line every so
+// many cells (set in the preferences).  The minor lines only show once
+// the cells are drawn as separate glyphs, while the major lines stay as
+// long as they're far enough apart to be any use.  The rulers label the
+// edges of the cells along the top and left of the sim, and follow it as
+// it pans and zooms.
+
+const (
+	minMajorGridGap = 8  // points between major grid lines, any closer and they're left out
+	minRulerGap     = 60 // points between ruler labels, at least
+	rulerAlpha      = 192
+)
+
+// A gridEdge is the leading edge of a cell along one axis
+type gridEdge struct {
+	Cell  golife.Coord // which column or row of cells it's the edge of
+	Pos   float32      // where it falls in the window
+	Major bool
+}
+
+// gridOverlay is what the grid and rulers need, worked out in Draw and
+// turned into canvas objects on the main goroutine
+type gridOverlay struct {
+	Size           fyne.Size
+	Columns, Rows  []gridEdge // grid lines, nil when the grid is hidden
+	LabelX, LabelY []gridEdge // ruler labels, nil when the rulers are hidden
+	Color          color.Color
+	Background     color.Color
+}
+
+// floorMultiple rounds down to a multiple of step, even for negative values
+func floorMultiple(value, step golife.Coord) golife.Coord {
+	remainder := value % step
+	if remainder < 0 {
+		remainder += step
+	}
+	return value - remainder
+}
+
+// cellEdges returns the leading edges of every step'th cell that are in
+// the window along one axis, using the same arithmetic as Draw.
+func (ls *LifeSim) cellEdges(step, major golife.Coord, windowLength, windowCenter, displayCenter float32) []gridEdge {
+	first := golife.Coord(math.Floor(float64(displayCenter - windowCenter/ls.Scale + 0.5)))
+	first = floorMultiple(first, step)
+	var edges []gridEdge
+	for cell := first; ; cell += step {
+		pos := windowCenter + ls.Scale*(float32(cell)-displayCenter) - ls.Scale/2.0
+		if pos >= windowLength {
+			return edges
+		}
+		if pos >= 0 {
+			edges = append(edges, gridEdge{Cell: cell, Pos: pos, Major: cell%major == 0})
+		}
+	}
+}
+
+// rulerStep is how many cells apart to put the ruler labels: the major
+// line spacing times 1, 2 or 5 times a power of ten, whichever is the
+// first to be far enough apart.
+func (ls *LifeSim) rulerStep(major golife.Coord) golife.Coord {
+	step := major
+	for power := golife.Coord(1); power <= math.MaxInt64/(major*50); power *= 10 {
+		for _, factor := range []golife.Coord{1, 2, 5} {
+			step = major * factor * power
+			if float32(step)*ls.Scale >= minRulerGap {
+				return step
+			}
+		}
+	}
+	return step
+}
+
+// newGridOverlay works out where the grid lines and ruler labels go
+func (ls *LifeSim) newGridOverlay(windowSize fyne.Size, windowCenter, displayCenter fyne.Position) *gridOverlay {
+	showGrid, showRulers := Config.ShowGrid(), Config.ShowRulers()
+	if (!showGrid && !showRulers) || ls.Scale <= 0 {
+		return nil
+	}
+	overlay := &gridOverlay{Size: windowSize, Color: Config.GridColor(), Background: Config.BackgroundColor()}
+	major := golife.Coord(Config.GridSpacing())
+	if showGrid {
+		step := golife.Coord(0)
+		if ls.Scale >= glyphScaleThreshold {
+			step = 1
+		} else if float32(major)*ls.Scale >= minMajorGridGap {
+			step = major
+		}
+		if step > 0 {
+			overlay.Columns = ls.cellEdges(step, major, windowSize.Width, windowCenter.X, displayCenter.X)
+			overlay.Rows = ls.cellEdges(step, major, windowSize.Height, windowCenter.Y, displayCenter.Y)
+		}
+	}
+	if showRulers {
+		step := ls.rulerStep(major)
+		overlay.LabelX = ls.cellEdges(step, major, windowSize.Width, windowCenter.X, displayCenter.X)
+		overlay.LabelY = ls.cellEdges(step, major, windowSize.Height, windowCenter.Y, displayCenter.Y)
+	}
+	return overlay
+}
+
+// minorColor is the major line color faded halfway into the background
+func (overlay *gridOverlay) minorColor() color.Color {
+	return blendColors(overlay.Background, overlay.Color, 0.5)
+}
+
+// markGridPixels draws the grid into the raster's pixels, where there
+// aren't any cells
+func (ls *LifeSim) markGridPixels(overlay *gridOverlay) {
+	mark := func(px, py int) {
+		if px >= 0 && px < ls.screenCols && py >= 0 && py < ls.screenRows && ls.screenCells[py*ls.screenCols+px] == pixelEmpty {
+			ls.screenCells[py*ls.screenCols+px] = pixelGrid
+		}
+	}
+	for _, edge := range overlay.Columns {
+		for py := 0; py < ls.screenRows; py++ {
+			mark(int(edge.Pos), py)
+		}
+	}
+	for _, edge := range overlay.Rows {
+		for px := 0; px < ls.screenCols; px++ {
+			mark(px, int(edge.Pos))
+		}
+	}
+}
+
+// gridCanvas keeps the canvas objects for the grid and rulers, to reuse
+// from frame to frame.  They're only touched on the main goroutine.
+type gridCanvas struct {
+	lines      []*canvas.Line
+	rulerBars  [2]*canvas.Rectangle // top and left
+	rulerTicks []*canvas.Line
+	labels     []*canvas.Text
+}
+
+func (gc *gridCanvas) line(index int) *canvas.Line {
+	for len(gc.lines) <= index {
+		gc.lines = append(gc.lines, canvas.NewLine(color.Transparent))
+	}
+	return gc.lines[index]
+}
+
+func (gc *gridCanvas) tick(index int) *canvas.Line {
+	for len(gc.rulerTicks) <= index {
+		gc.rulerTicks = append(gc.rulerTicks, canvas.NewLine(color.Transparent))
+	}
+	return gc.rulerTicks[index]
+}
+
+func (gc *gridCanvas) label(index int) *canvas.Text {
+	for len(gc.labels) <= index {
+		gc.labels = append(gc.labels, canvas.NewText("", color.Transparent))
+	}
+	return gc.labels[index]
+}
+
+// lineObjects returns the grid lines as canvas objects
+func (gc *gridCanvas) lineObjects(overlay *gridOverlay) []fyne.CanvasObject {
+	objects := make([]fyne.CanvasObject, 0, len(overlay.Columns)+len(overlay.Rows))
+	minorColor := overlay.minorColor()
+	addLine := func(edge gridEdge, from, to fyne.Position) {
+		line := gc.line(len(objects))
+		line.StrokeColor, line.StrokeWidth = minorColor, 1
+		if edge.Major {
+			line.StrokeColor = overlay.Color
+		}
+		line.Position1, line.Position2 = from, to
+		objects = append(objects, line)
+	}
+	for _, edge := range overlay.Columns {
+		addLine(edge, fyne.NewPos(edge.Pos, 0), fyne.NewPos(edge.Pos, overlay.Size.Height))
+	}
+	for _, edge := range overlay.Rows {
+		addLine(edge, fyne.NewPos(0, edge.Pos), fyne.NewPos(overlay.Size.Width, edge.Pos))
+	}
+	return objects
+}
+
+// rulerObjects returns the rulers along the top and left edges as canvas
+// objects.  The left one is as wide as its widest label.
+func (gc *gridCanvas) rulerObjects(overlay *gridOverlay) []fyne.CanvasObject {
+	if overlay.LabelX == nil && overlay.LabelY == nil {
+		return nil
+	}
+	if gc.rulerBars[0] == nil {
+		gc.rulerBars = [2]*canvas.Rectangle{canvas.NewRectangle(color.Transparent), canvas.NewRectangle(color.Transparent)}
+	}
+	textSize, padding := theme.CaptionTextSize(), theme.Padding()
+	labelText := func(edge gridEdge) string {
+		return strconv.FormatInt(int64(edge.Cell), 10)
+	}
+	topHeight := fyne.MeasureText("0", textSize, fyne.TextStyle{}).Height + 2*padding
+	leftWidth := topHeight
+	for _, edge := range overlay.LabelY {
+		leftWidth = max(leftWidth, fyne.MeasureText(labelText(edge), textSize, fyne.TextStyle{}).Width+3*padding)
+	}
+
+	top, left := gc.rulerBars[0], gc.rulerBars[1]
+	for _, bar := range gc.rulerBars {
+		bar.FillColor = withAlpha(overlay.Background, rulerAlpha)
+		bar.StrokeColor, bar.StrokeWidth = overlay.Color, 1
+	}
+	top.Move(fyne.NewPos(0, 0))
+	top.Resize(fyne.NewSize(overlay.Size.Width, topHeight))
+	left.Move(fyne.NewPos(0, 0))
+	left.Resize(fyne.NewSize(leftWidth, overlay.Size.Height))
+	objects := []fyne.CanvasObject{top, left}
+
+	// each label sits just after a tick marking the edge of its cell
+	count := 0
+	addLabel := func(edge gridEdge, tickFrom, tickTo, labelAt fyne.Position) {
+		tick := gc.tick(count)
+		tick.StrokeColor, tick.StrokeWidth = overlay.Color, 1
+		tick.Position1, tick.Position2 = tickFrom, tickTo
+		label := gc.label(count)
+		label.Text, label.Color, label.TextSize = labelText(edge), overlay.Color, textSize
+		label.Move(labelAt)
+		label.Resize(label.MinSize())
+		objects = append(objects, tick, label)
+		count++
+	}
+	for _, edge := range overlay.LabelX {
+		if edge.Pos >= leftWidth {
+			addLabel(edge, fyne.NewPos(edge.Pos, 0), fyne.NewPos(edge.Pos, topHeight),
+				fyne.NewPos(edge.Pos+padding, padding))
+		}
+	}
+	for _, edge := range overlay.LabelY {
+		if edge.Pos >= topHeight {
+			addLabel(edge, fyne.NewPos(0, edge.Pos), fyne.NewPos(leftWidth, edge.Pos),
+				fyne.NewPos(padding, edge.Pos))
+		}
+	}
+	return objects
+}
@@ -0,0 +1,409 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/pneumaticdeath/golife"
+)
+
+// HashLife is an alternative engine for running huge or long lived
+// patterns.  The universe is stored as a quadtree where identical
+// subtrees are shared, and the result of running each subtree forward
+// is remembered, so repetitive patterns (breeders, metacells, guns) can
+// be run forward by enormous numbers of generations at a time.
+//
+// A node at level k is a square of 2^k by 2^k cells.  The root is
+// always centered on the origin, so it covers -2^(k-1) up to 2^(k-1)-1
+// in both directions.  The engine works for any rule that isn't a
+// Generations rule (and doesn't have B0, which we never allow anyway).
+
+const (
+	maxHashLifeStepLog = 30      // the biggest step is 2^30 generations
+	maxHashLifeNodes   = 4000000 // forget cached results when we have this many nodes
+)
+
+type hlNode struct {
+	nw, ne, sw, se *hlNode
+	level          uint8
+	population     int64
+	next           *hlNode // cached center of this node run forward 2^nextStep generations
+	nextStep       int8
+}
+
+type hlKey struct {
+	nw, ne, sw, se *hlNode
+}
+
+type HashLife struct {
+	Rule  Rule
+	root  *hlNode
+	nodes map[hlKey]*hlNode
+	alive *hlNode
+	dead  *hlNode
+	empty []*hlNode // empty node at each level, built as needed
+}
+
+func NewHashLife(rule Rule) *HashLife {
+	h := &HashLife{Rule: rule}
+	h.reset()
+	return h
+}
+
+func (h *HashLife) reset() {
+	h.nodes = make(map[hlKey]*hlNode)
+	h.alive = &hlNode{population: 1, nextStep: -1}
+	h.dead = &hlNode{nextStep: -1}
+	h.empty = []*hlNode{h.dead}
+	h.root = h.emptyNode(3)
+}
+
+// join returns the canonical node with the given quadrants
+func (h *HashLife) join(nw, ne, sw, se *hlNode) *hlNode {
+	key := hlKey{nw, ne, sw, se}
+	if node, found := h.nodes[key]; found {
+		return node
+	}
+	node := &hlNode{nw: nw, ne: ne, sw: sw, se: se, level: nw.level + 1, nextStep: -1,
+		population: nw.population + ne.population + sw.population + se.population}
+	h.nodes[key] = node
+	return node
+}
+
+func (h *HashLife) emptyNode(level uint8) *hlNode {
+	for len(h.empty) <= int(level) {
+		e := h.empty[len(h.empty)-1]
+		h.empty = append(h.empty, h.join(e, e, e, e))
+	}
+	return h.empty[level]
+}
+
+// SetPopulation replaces the universe with the cells in the population.
+// Cached results are kept, so going back and forth between the engines
+// doesn't throw away everything HashLife has learned about a pattern.
+func (h *HashLife) SetPopulation(pop golife.Population) {
+	cells := make([]golife.Cell, 0, len(pop))
+	var extent golife.Coord
+	for cell := range pop {
+		cells = append(cells, cell)
+		extent = max(extent, -cell.X, -cell.Y, cell.X+1, cell.Y+1)
+	}
+	level := uint8(3)
+	for golife.Coord(1)<<(level-1) < extent {
+		level++
+	}
+	half := golife.Coord(1) << (level - 1)
+	h.root = h.build(cells, -half, -half, level)
+}
+
+func (h *HashLife) build(cells []golife.Cell, x, y golife.Coord, level uint8) *hlNode {
+	if len(cells) == 0 {
+		return h.emptyNode(level)
+	}
+	if level == 0 {
+		return h.alive
+	}
+	half := golife.Coord(1) << (level - 1)
+	var quadrants [4][]golife.Cell
+	for _, cell := range cells {
+		index := 0
+		if cell.X >= x+half {
+			index += 1
+		}
+		if cell.Y >= y+half {
+			index += 2
+		}
+		quadrants[index] = append(quadrants[index], cell)
+	}
+	return h.join(h.build(quadrants[0], x, y, level-1), h.build(quadrants[1], x+half, y, level-1),
+		h.build(quadrants[2], x, y+half, level-1), h.build(quadrants[3], x+half, y+half, level-1))
+}
+
+// Size returns the number of live cells
+func (h *HashLife) Size() int {
+	return int(h.root.population)
+}
+
+func (h *HashLife) origin() golife.Coord {
+	return -(golife.Coord(1) << (h.root.level - 1))
+}
+
+// Visit calls fn for every live part of the universe that overlaps the
+// box from minCell to maxCell.  Nodes that are no bigger than minSize
+// cells across aren't broken down any further, so when drawing a zoomed
+// out view we don't have to look at every cell.  For single cells size
+// is 1.
+func (h *HashLife) Visit(minCell, maxCell golife.Cell, minSize golife.Coord, fn func(x, y, size golife.Coord)) {
+	root := h.root // the root changes when the engine steps
+	origin := -(golife.Coord(1) << (root.level - 1))
+	h.visit(root, origin, origin, minCell, maxCell, max(minSize, 1), fn)
+}
+
+func (h *HashLife) visit(node *hlNode, x, y golife.Coord, minCell, maxCell golife.Cell, minSize golife.Coord, fn func(x, y, size golife.Coord)) {
+	size := golife.Coord(1) << node.level
+	if node.population == 0 || x > maxCell.X || y > maxCell.Y || x+size <= minCell.X || y+size <= minCell.Y {
+		return
+	}
+	if node.level == 0 || size <= minSize {
+		fn(x, y, size)
+		return
+	}
+	half := size / 2
+	h.visit(node.nw, x, y, minCell, maxCell, minSize, fn)
+	h.visit(node.ne, x+half, y, minCell, maxCell, minSize, fn)
+	h.visit(node.sw, x, y+half, minCell, maxCell, minSize, fn)
+	h.visit(node.se, x+half, y+half, minCell, maxCell, minSize, fn)
+}
+
+// Population builds the full population map for the universe
+func (h *HashLife) Population() golife.Population {
+	pop := make(golife.Population, h.Size())
+	extent := golife.Cell{X: -h.origin() - 1, Y: -h.origin() - 1}
+	h.Visit(golife.Cell{X: h.origin(), Y: h.origin()}, extent, 1, func(x, y, _ golife.Coord) {
+		pop[golife.Cell{X: x, Y: y}] = true
+	})
+	return pop
+}
+
+// BoundingBox finds the live cells furthest out in each direction.
+func (h *HashLife) BoundingBox() (golife.Cell, golife.Cell) {
+	if h.root.population == 0 {
+		return golife.Cell{}, golife.Cell{}
+	}
+	// each search looks for the smallest value of a key for the block,
+	// looking at the most promising quadrants first
+	minX := h.extreme(func(x, y, size golife.Coord) golife.Coord { return x })
+	minY := h.extreme(func(x, y, size golife.Coord) golife.Coord { return y })
+	maxX := -h.extreme(func(x, y, size golife.Coord) golife.Coord { return -(x + size - 1) })
+	maxY := -h.extreme(func(x, y, size golife.Coord) golife.Coord { return -(y + size - 1) })
+	return golife.Cell{X: minX, Y: minY}, golife.Cell{X: maxX, Y: maxY}
+}
+
+func (h *HashLife) extreme(key func(x, y, size golife.Coord) golife.Coord) golife.Coord {
+	type block struct {
+		node *hlNode
+		x, y golife.Coord
+	}
+	var best golife.Coord
+	found := false
+	var search func(b block)
+	search = func(b block) {
+		size := golife.Coord(1) << b.node.level
+		if b.node.population == 0 || (found && key(b.x, b.y, size) >= best) {
+			return
+		}
+		if b.node.level == 0 {
+			best, found = key(b.x, b.y, 1), true
+			return
+		}
+		half := size / 2
+		children := []block{{b.node.nw, b.x, b.y}, {b.node.ne, b.x + half, b.y},
+			{b.node.sw, b.x, b.y + half}, {b.node.se, b.x + half, b.y + half}}
+		sort.Slice(children, func(i, j int) bool {
+			return key(children[i].x, children[i].y, half) < key(children[j].x, children[j].y, half)
+		})
+		for _, child := range children {
+			search(child)
+		}
+	}
+	search(block{h.root, h.origin(), h.origin()})
+	return best
+}
+
+// expand doubles the size of the universe, keeping the current contents
+// in the middle.
+func (h *HashLife) expand() {
+	e := h.emptyNode(h.root.level - 1)
+	r := h.root
+	h.root = h.join(h.join(e, e, e, r.nw), h.join(e, e, r.ne, e),
+		h.join(e, r.sw, e, e), h.join(r.se, e, e, e))
+}
+
+// centered reports whether all of the live cells are in the middle
+// quarter of the universe, which leaves enough room around them for the
+// pattern to grow while we step.
+func (h *HashLife) centered() bool {
+	r := h.root
+	return r.nw.population == r.nw.se.se.population && r.ne.population == r.ne.sw.sw.population &&
+		r.sw.population == r.sw.ne.ne.population && r.se.population == r.se.nw.nw.population
+}
+
+// Step runs the universe forward 2^stepLog generations
+func (h *HashLife) Step(stepLog int) {
+	stepLog = min(max(stepLog, 0), maxHashLifeStepLog)
+	for int(h.root.level) < stepLog+3 || !h.centered() {
+		h.expand()
+	}
+	h.root = h.successor(h.root, stepLog)
+	if len(h.nodes) > maxHashLifeNodes {
+		h.collect()
+	}
+}
+
+// successor returns the center half of the node run forward by 2^step
+// generations, or 2^(level-2) generations if that is smaller.
+func (h *HashLife) successor(node *hlNode, step int) *hlNode {
+	if node.population == 0 {
+		return h.emptyNode(node.level - 1)
+	}
+	step = min(step, int(node.level)-2)
+	if node.next != nil && int(node.nextStep) == step {
+		return node.next
+	}
+
+	var result *hlNode
+	if node.level == 2 {
+		result = h.baseStep(node)
+	} else {
+		nw, ne, sw, se := node.nw, node.ne, node.sw, node.se
+		c1 := h.successor(nw, step)
+		c2 := h.successor(h.join(nw.ne, ne.nw, nw.se, ne.sw), step)
+		c3 := h.successor(ne, step)
+		c4 := h.successor(h.join(nw.sw, nw.se, sw.nw, sw.ne), step)
+		c5 := h.successor(h.join(nw.se, ne.sw, sw.ne, se.nw), step)
+		c6 := h.successor(h.join(ne.sw, ne.se, se.nw, se.ne), step)
+		c7 := h.successor(sw, step)
+		c8 := h.successor(h.join(sw.ne, se.nw, sw.se, se.sw), step)
+		c9 := h.successor(se, step)
+		if step < int(node.level)-2 {
+			// the sub-results are already far enough along, so just
+			// take the middles
+			result = h.join(h.join(c1.se, c2.sw, c4.ne, c5.nw), h.join(c2.se, c3.sw, c5.ne, c6.nw),
+				h.join(c4.se, c5.sw, c7.ne, c8.nw), h.join(c5.se, c6.sw, c8.ne, c9.nw))
+		} else {
+			result = h.join(h.successor(h.join(c1, c2, c4, c5), step), h.successor(h.join(c2, c3, c5, c6), step),
+				h.successor(h.join(c4, c5, c7, c8), step), h.successor(h.join(c5, c6, c8, c9), step))
+		}
+	}
+
+	node.next, node.nextStep = result, int8(step)
+	return result
+}
+
+// baseStep runs a 4x4 node forward one generation the slow way, giving
+// the 2x2 center.
+func (h *HashLife) baseStep(node *hlNode) *hlNode {
+	var grid [4][4]bool
+	quads := [4]*hlNode{node.nw, node.ne, node.sw, node.se}
+	for q, quad := range quads {
+		cells := [4]*hlNode{quad.nw, quad.ne, quad.sw, quad.se}
+		for c, cell := range cells {
+			grid[(q/2)*2+c/2][(q%2)*2+c%2] = cell.population > 0
+		}
+	}
+	nextCell := func(row, col int) *hlNode {
+		count := 0
+		for dr := -1; dr <= 1; dr++ {
+			for dc := -1; dc <= 1; dc++ {
+				if (dr != 0 || dc != 0) && grid[row+dr][col+dc] {
+					count++
+				}
+			}
+		}
+		mask := h.Rule.Birth
+		if grid[row][col] {
+			mask = h.Rule.Survive
+		}
+		if mask&(1<<count) != 0 {
+			return h.alive
+		}
+		return h.dead
+	}
+	return h.join(nextCell(1, 1), nextCell(1, 2), nextCell(2, 1), nextCell(2, 2))
+}
+
+// collect throws away every node that isn't part of the current
+// universe, along with all the cached results.
+func (h *HashLife) collect() {
+	oldRoot := h.root
+	h.nodes = make(map[hlKey]*hlNode)
+	h.empty = []*hlNode{h.dead}
+	copies := make(map[*hlNode]*hlNode)
+	var rebuild func(node *hlNode) *hlNode
+	rebuild = func(node *hlNode) *hlNode {
+		if node.level == 0 {
+			return node
+		}
+		if c, found := copies[node]; found {
+			return c
+		}
+		c := h.join(rebuild(node.nw), rebuild(node.ne), rebuild(node.sw), rebuild(node.se))
+		copies[node] = c
+		return c
+	}
+	h.root = rebuild(oldRoot)
+}
+
+// The LifeSim keeps the golife.Game as the master copy of the population
+// while editing, and only builds the quadtree when HashLife starts
+// stepping.  While HashLife is running the population map falls behind,
+// and it is only rebuilt (by SyncPopulation) when something needs it.
+
+// SetHashLife switches the sim between the standard engine and HashLife
+func (ls *LifeSim) SetHashLife(on bool) {
+	if on == (ls.hashLife != nil) {
+		return
+	}
+	ls.SyncPopulation()
+	if on {
+		ls.hashLife = NewHashLife(ls.Rule)
+	} else {
+		ls.hashLife = nil
+	}
+}
+
+// UsingHashLife reports whether the sim will step with HashLife.  Generations
+// rules always use the standard engine.
+func (ls *LifeSim) UsingHashLife() bool {
+	return ls.hashLife != nil && !ls.Rule.IsGenerations()
+}
+
+// GenerationsPerStep returns how many generations each step moves on
+func (ls *LifeSim) GenerationsPerStep() int {
+	if ls.UsingHashLife() {
+		return 1 << ls.StepLog
+	}
+	return 1
+}
+
+func (ls *LifeSim) stepHashLife() {
+	if !ls.treeAhead {
+		if ls.hashLife.Rule != ls.Rule {
+			ls.hashLife = NewHashLife(ls.Rule)
+		}
+		ls.hashLife.SetPopulation(ls.Game.Population)
+		// the generation history doesn't mean anything once we start
+		// jumping ahead
+		ls.Game.History = nil
+		ls.dyingHistory = nil
+	}
+	ls.hashLife.Step(ls.StepLog)
+	ls.Game.Generation += 1 << ls.StepLog
+	ls.treeAhead = true
+}
+
+// SyncPopulation brings the game's population up to date with the
+// HashLife universe.
+func (ls *LifeSim) SyncPopulation() {
+	if ls.treeAhead {
+		ls.Game.Population = ls.hashLife.Population()
+		ls.treeAhead = false
+	}
+}
+
+// CellCount returns the number of live cells without having to sync the
+// population.
+func (ls *LifeSim) CellCount() int {
+	if ls.treeAhead {
+		return ls.hashLife.Size()
+	}
+	return ls.Game.Size()
+}
+
+// BoundingBox returns the corners of the live cells without having to
+// sync the population.
+func (ls *LifeSim) BoundingBox() (golife.Cell, golife.Cell) {
+	if ls.treeAhead {
+		return ls.hashLife.BoundingBox()
+	}
+	return ls.Game.Population.BoundingBox()
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/pneumaticdeath/golife"
+)
+
+// randomSoup fills a square at an offset from the origin, so the tree
+// has to cover cells on both sides of it
+func randomSoup(rng *rand.Rand, side int, offset golife.Coord) golife.Population {
+	pop := make(golife.Population)
+	for x := range side {
+		for y := range side {
+			if rng.IntN(2) == 0 {
+				pop[golife.Cell{X: golife.Coord(x) + offset, Y: golife.Coord(y) - offset}] = true
+			}
+		}
+	}
+	return pop
+}
+
+func TestHashLifeMatchesStep(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	for _, ruleStr := range []string{"B3/S23", "B36/S23", "B2/S", "B3678/S34678"} {
+		rule, err := ParseRule(ruleStr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for stepLog := range 6 {
+			t.Run(fmt.Sprintf("%s/2^%d", ruleStr, stepLog), func(t *testing.T) {
+				pop := randomSoup(rng, 16, -5)
+				hashLife := NewHashLife(rule)
+				hashLife.SetPopulation(pop)
+				for step := range 3 {
+					hashLife.Step(stepLog)
+					for range 1 << stepLog {
+						pop = rule.Step(pop)
+					}
+					if got, want := populationCells(hashLife.Population()), populationCells(pop); !slices.Equal(got, want) {
+						t.Fatalf("after %d steps got %d cells, want %d", step+1, len(got), len(want))
+					}
+					if hashLife.Size() != len(pop) {
+						t.Errorf("after %d steps Size() = %d, want %d", step+1, hashLife.Size(), len(pop))
+					}
+					if len(pop) == 0 {
+						continue
+					}
+					gotMin, gotMax := hashLife.BoundingBox()
+					wantMin, wantMax := pop.BoundingBox()
+					if gotMin != wantMin || gotMax != wantMax {
+						t.Errorf("after %d steps the box is %v to %v, want %v to %v", step+1, gotMin, gotMax, wantMin, wantMax)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestHashLifeGliderTravels(t *testing.T) {
+	hashLife := NewHashLife(ConwayRule)
+	pop := make(golife.Population)
+	for _, cell := range testGlider {
+		pop[cell] = true
+	}
+	hashLife.SetPopulation(pop)
+	hashLife.Step(10) // 1024 generations, so it's moved 256 cells diagonally
+	want := make([]golife.Cell, 0, len(testGlider))
+	for _, cell := range testGlider {
+		want = append(want, golife.Cell{X: cell.X + 256, Y: cell.Y + 256})
+	}
+	if got := populationCells(hashLife.Population()); !slices.Equal(got, sortedCells(want)) {
+		t.Errorf("got %v, want %v", got, sortedCells(want))
+	}
+	if hashLife.Size() != 5 {
+		t.Errorf("Size() = %d, want 5", hashLife.Size())
+	}
+}
+
+func TestHashLifeEmpty(t *testing.T) {
+	hashLife := NewHashLife(ConwayRule)
+	hashLife.SetPopulation(golife.Population{})
+	hashLife.Step(3)
+	if hashLife.Size() != 0 || len(hashLife.Population()) != 0 {
+		t.Error("an empty universe came to life")
+	}
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pneumaticdeath/golife"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Exporting images of the pattern.  The rendering doesn't touch the
+// screen at all, it just needs the pattern and an ImageExport describing
+// what to draw and how, so it works just as well without a display.
+
+const (
+	maxExportImageSize = 16384    // pixels on a side
+	maxExportPixels    = 64000000 // in all, since the image is held in memory at four bytes a pixel
+)
+
+type ImageExport struct {
+	Min, Max    golife.Cell   // the corners of the part of the game to draw, inclusive
+	CellSize    int           // pixels per cell
+	GridLines   bool          // draw lines between the cells
+	GlyphStyle  string        // same as LifeSim.GlyphStyle
+	Background  color.Color   // background color
+	StateColors []color.Color // color of the cells in each state, 1 is live cells
+	GridColor   color.Color   // color of the grid lines
+}
+
+// NewImageExport sets up an export with the sim's colors and glyph style,
+// covering either the current view or the whole pattern.
+func (ls *LifeSim) NewImageExport(wholePattern bool, cellSize int, gridLines bool) *ImageExport {
+	ie := &ImageExport{CellSize: cellSize, GridLines: gridLines, GlyphStyle: ls.GlyphStyle,
+		Background: Config.BackgroundColor()}
+	ie.StateColors = make([]color.Color, max(ls.Rule.States, 2))
+	for state := range ie.StateColors {
+		ie.StateColors[state] = ls.StateColor(uint8(state))
+	}
+	ie.GridColor = blendColors(ie.Background, ie.StateColors[1], 0.25)
+	if wholePattern {
+		ie.Min, ie.Max = PatternBoundingBox(ls.Pattern())
+	} else {
+		ie.Min, ie.Max = ls.VisibleBox()
+	}
+	return ie
+}
+
+// PatternBoundingBox returns the corners of the pattern including any
+// dying cells.
+func PatternBoundingBox(pattern *Pattern) (golife.Cell, golife.Cell) {
+	minCell, maxCell := pattern.Game.Population.BoundingBox()
+	first := pattern.Game.Size() == 0
+	for cell := range pattern.Dying {
+		if first {
+			minCell, maxCell, first = cell, cell, false
+		}
+		minCell = golife.Cell{X: min(minCell.X, cell.X), Y: min(minCell.Y, cell.Y)}
+		maxCell = golife.Cell{X: max(maxCell.X, cell.X), Y: max(maxCell.Y, cell.Y)}
+	}
+	return minCell, maxCell
+}
+
+// VisibleBox returns the corners of the cells that are at least partly
+// visible on screen.
+func (ls *LifeSim) VisibleBox() (golife.Cell, golife.Cell) {
+	windowSize := ls.drawingSurface.Size()
+	if ls.Scale <= 0 || windowSize.Width == 0 || windowSize.Height == 0 {
+		return golife.Cell{X: golife.Coord(ls.BoxDisplayMin.X), Y: golife.Coord(ls.BoxDisplayMin.Y)},
+			golife.Cell{X: golife.Coord(ls.BoxDisplayMax.X), Y: golife.Coord(ls.BoxDisplayMax.Y)}
+	}
+	centerX := float64(ls.BoxDisplayMax.X+ls.BoxDisplayMin.X) / 2.0
+	centerY := float64(ls.BoxDisplayMax.Y+ls.BoxDisplayMin.Y) / 2.0
+	halfWidth := float64(windowSize.Width/ls.Scale) / 2.0
+	halfHeight := float64(windowSize.Height/ls.Scale) / 2.0
+	return golife.Cell{X: golife.Coord(math.Ceil(centerX - halfWidth - 0.5)), Y: golife.Coord(math.Ceil(centerY - halfHeight - 0.5))},
+		golife.Cell{X: golife.Coord(math.Floor(centerX + halfWidth + 0.5)), Y: golife.Coord(math.Floor(centerY + halfHeight + 0.5))}
+}
+
+// ImageSize returns the size of the image in pixels
+func (ie *ImageExport) ImageSize() (int, int) {
+	return int(ie.Max.X-ie.Min.X+1) * ie.CellSize, int(ie.Max.Y-ie.Min.Y+1) * ie.CellSize
+}
+
+func (ie *ImageExport) check() error {
+	if ie.CellSize < 1 {
+		return errors.New("Need at least one pixel per cell")
+	}
+	if ie.Max.X < ie.Min.X || ie.Max.Y < ie.Min.Y {
+		return errors.New("Nothing to export")
+	}
+	if ie.Max.X-ie.Min.X >= maxExportImageSize || ie.Max.Y-ie.Min.Y >= maxExportImageSize {
+		return fmt.Errorf("Image would be more than %d pixels across", maxExportImageSize)
+	}
+	width, height := ie.ImageSize()
+	if width > maxExportImageSize || height > maxExportImageSize {
+		return fmt.Errorf("Image would be more than %d pixels across, try fewer pixels per cell", maxExportImageSize)
+	}
+	if width*height > maxExportPixels {
+		return errors.New("Image would be too big, try a smaller region or fewer pixels per cell")
+	}
+	return nil
+}
+
+// forEachCell calls fn for every cell in the region along with its state
+func (ie *ImageExport) forEachCell(pattern *Pattern, fn func(cell golife.Cell, state uint8)) {
+	for cell, state := range pattern.Dying {
+		if inBox(cell, ie.Min, ie.Max) && int(state) < len(ie.StateColors) {
+			fn(cell, state)
+		}
+	}
+	for cell := range pattern.Game.Population {
+		if inBox(cell, ie.Min, ie.Max) {
+			fn(cell, 1)
+		}
+	}
+}
+
+// glyphInset is how far in from the edge of its square a cell is drawn,
+// like the gap the screen leaves between cells.  Tiny cells fill their
+// whole square.
+func (ie *ImageExport) glyphInset() float64 {
+	if ie.CellSize < 5 {
+		return 0
+	}
+	return float64(ie.CellSize) / 20.0
+}
+
+// Render draws the region of the pattern into a new image
+func (ie *ImageExport) Render(pattern *Pattern) (*image.NRGBA, error) {
+	if err := ie.check(); err != nil {
+		return nil, err
+	}
+	width, height := ie.ImageSize()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	bg := color.NRGBAModel.Convert(ie.Background)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, bg)
+		}
+	}
+
+	if ie.GridLines && ie.CellSize >= 4 {
+		grid := color.NRGBAModel.Convert(ie.GridColor)
+		for x := 0; x < width; x += ie.CellSize {
+			for y := 0; y < height; y++ {
+				img.Set(x, y, grid)
+			}
+		}
+		for y := 0; y < height; y += ie.CellSize {
+			for x := 0; x < width; x++ {
+				img.Set(x, y, grid)
+			}
+		}
+	}
+
+	size := float64(ie.CellSize)
+	inset := ie.glyphInset()
+	radius := 0.0
+	switch ie.GlyphStyle {
+	case "RoundedRectangle":
+		radius = size / 5.0
+	case "Circle":
+		radius = (size - 2*inset) / 2.0
+	}
+	ie.forEachCell(pattern, func(cell golife.Cell, state uint8) {
+		clr := color.NRGBAModel.Convert(ie.StateColors[state])
+		left := int(cell.X-ie.Min.X) * ie.CellSize
+		top := int(cell.Y-ie.Min.Y) * ie.CellSize
+		for py := 0; py < ie.CellSize; py++ {
+			for px := 0; px < ie.CellSize; px++ {
+				// sample the middle of each pixel
+				if insideGlyph(float64(px)+0.5, float64(py)+0.5, inset, size-inset, radius) {
+					img.Set(left+px, top+py, clr)
+				}
+			}
+		}
+	})
+	return img, nil
+}
+
+// insideGlyph reports whether the point is inside a square from lo to hi
+// in both directions with corners rounded off by radius.
+func insideGlyph(x, y, lo, hi, radius float64) bool {
+	if x < lo || x > hi || y < lo || y > hi {
+		return false
+	}
+	// find the nearest corner circle center, if the point is in a corner
+	cx := min(max(x, lo+radius), hi-radius)
+	cy := min(max(y, lo+radius), hi-radius)
+	return (x-cx)*(x-cx)+(y-cy)*(y-cy) <= radius*radius
+}
+
+// WritePNG renders the region and writes it as a PNG
+func (ie *ImageExport) WritePNG(pattern *Pattern, writer io.Writer) error {
+	img, err := ie.Render(pattern)
+	if err != nil {
+		return err
+	}
+	return png.Encode(writer, img)
+}
+
+func svgColor(clr color.Color) (string, string) {
+	c := color.NRGBAModel.Convert(clr).(color.NRGBA)
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B), strconv.FormatFloat(float64(c.A)/255.0, 'f', 3, 64)
+}
+
+// WriteSVG writes the region as an SVG image, with one shape per cell
+func (ie *ImageExport) WriteSVG(pattern *Pattern, writer io.Writer) error {
+	if err := ie.check(); err != nil {
+		return err
+	}
+	width, height := ie.ImageSize()
+	out := bufio.NewWriter(writer)
+	fmt.Fprintf(out, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", width, height, width, height)
+	fill, opacity := svgColor(ie.Background)
+	fmt.Fprintf(out, "<rect width=\"%d\" height=\"%d\" fill=\"%s\" fill-opacity=\"%s\"/>\n", width, height, fill, opacity)
+
+	if ie.GridLines && ie.CellSize >= 4 {
+		var path strings.Builder
+		for x := 0; x <= width; x += ie.CellSize {
+			fmt.Fprintf(&path, "M%d 0V%d", x, height)
+		}
+		for y := 0; y <= height; y += ie.CellSize {
+			fmt.Fprintf(&path, "M0 %dH%d", y, width)
+		}
+		stroke, strokeOpacity := svgColor(ie.GridColor)
+		fmt.Fprintf(out, "<path d=\"%s\" stroke=\"%s\" stroke-opacity=\"%s\" stroke-width=\"1\" fill=\"none\"/>\n", path.String(), stroke, strokeOpacity)
+	}
+
+	size := float64(ie.CellSize)
+	inset := ie.glyphInset()
+	glyph := size - 2*inset
+	ie.forEachCell(pattern, func(cell golife.Cell, state uint8) {
+		fill, opacity := svgColor(ie.StateColors[state])
+		x := float64(cell.X-ie.Min.X)*size + inset
+		y := float64(cell.Y-ie.Min.Y)*size + inset
+		switch ie.GlyphStyle {
+		case "Circle":
+			fmt.Fprintf(out, "<circle cx=\"%g\" cy=\"%g\" r=\"%g\" fill=\"%s\" fill-opacity=\"%s\"/>\n", x+glyph/2, y+glyph/2, glyph/2, fill, opacity)
+		case "RoundedRectangle":
+			fmt.Fprintf(out, "<rect x=\"%g\" y=\"%g\" width=\"%g\" height=\"%g\" rx=\"%g\" fill=\"%s\" fill-opacity=\"%s\"/>\n", x, y, glyph, glyph, size/5, fill, opacity)
+		default:
+			fmt.Fprintf(out, "<rect x=\"%g\" y=\"%g\" width=\"%g\" height=\"%g\" fill=\"%s\" fill-opacity=\"%s\"/>\n", x, y, glyph, glyph, fill, opacity)
+		}
+	})
+	fmt.Fprintln(out, "</svg>")
+	return out.Flush()
+}
+
+// ShowExportImageDialog asks how the image should be drawn, and then
+// where to save it.  The file extension picks between PNG and SVG.
+func ShowExportImageDialog(lc *LifeContainer) {
+	regionSelector := widget.NewRadioGroup([]string{"Current view", "Whole pattern"}, nil)
+	regionSelector.SetSelected("Current view")
+	regionSelector.Required = true
+	cellSizeEntry := widget.NewEntry()
+	cellSizeEntry.SetText("10")
+	cellSizeEntry.Validator = func(text string) error {
+		size, err := strconv.Atoi(text)
+		if err != nil || size < 1 || size > 100 {
+			return errors.New("Must be between 1 and 100")
+		}
+		return nil
+	}
+	gridCheck := widget.NewCheck("Grid lines", nil)
+
+	formItems := []*widget.FormItem{
+		widget.NewFormItem("Region", regionSelector),
+		widget.NewFormItem("Pixels per cell", cellSizeEntry),
+		widget.NewFormItem("", gridCheck),
+	}
+	dialog.ShowForm("Export image", "Export", "Cancel", formItems, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		cellSize, _ := strconv.Atoi(cellSizeEntry.Text)
+		export := lc.Sim.NewImageExport(regionSelector.Selected == "Whole pattern", cellSize, gridCheck.Checked)
+		pattern := lc.Sim.Pattern().Copy() // the sim might move on while the file dialog is up
+		if err := export.check(); err != nil {
+			dialog.ShowError(err, mainWindow)
+			return
+		}
+
+		imageFilter := &LongExtensionsFileFilter{Extensions: []string{".png", ".svg"}}
+		fileSave := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, mainWindow)
+				return
+			}
+			if writer == nil {
+				return
+			}
+			defer writer.Close()
+			if strings.HasSuffix(strings.ToLower(writer.URI().Name()), ".svg") {
+				err = export.WriteSVG(pattern, writer)
+			} else {
+				err = export.WritePNG(pattern, writer)
+			}
+			if err != nil {
+				dialog.ShowError(err, mainWindow)
+			}
+			if writer.URI().Scheme() == "file" {
+				Config.SetLastUsedDirURI(writer.URI())
+			}
+		}, mainWindow)
+		fileSave.SetFilter(imageFilter)
+		fileSave.SetFileName(strings.TrimSuffix(gameTitle(pattern.Game), filepath.Ext(gameTitle(pattern.Game))) + ".png")
+		fileSave.SetLocation(Config.LastUsedDirURI())
+		fileSave.Show()
+	}, mainWindow)
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/color"
+	"image/png"
+	"io"
+	"testing"
+
+	"github.com/pneumaticdeath/golife"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+)
+
+var (
+	testBackground = color.NRGBA{R: 0, G: 0, B: 0, A: 255}
+	testLive       = color.NRGBA{R: 255, G: 255, B: 0, A: 255}
+	testDying      = color.NRGBA{R: 0, G: 0, B: 255, A: 255}
+	testGrid       = color.NRGBA{R: 64, G: 64, B: 64, A: 255}
+)
+
+func newTestExport(minCell, maxCell golife.Cell, cellSize int, gridLines bool) *ImageExport {
+	return &ImageExport{Min: minCell, Max: maxCell, CellSize: cellSize, GridLines: gridLines, GlyphStyle: "Rectangle",
+		Background: testBackground, StateColors: []color.Color{testBackground, testLive, testDying}, GridColor: testGrid}
+}
+
+// renderPNG renders the pattern to a PNG and reads it back, so the test
+// sees what ends up in the file
+func renderPNG(t *testing.T, ie *ImageExport, pattern *Pattern) func(x, y int) color.NRGBA {
+	var buf bytes.Buffer
+	if err := ie.WritePNG(pattern, &buf); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	width, height := ie.ImageSize()
+	if bounds := img.Bounds(); bounds.Dx() != width || bounds.Dy() != height {
+		t.Fatalf("image is %dx%d, want %dx%d", bounds.Dx(), bounds.Dy(), width, height)
+	}
+	return func(x, y int) color.NRGBA {
+		return color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+	}
+}
+
+func TestRenderPNG(t *testing.T) {
+	pattern := newTestPattern(golife.Cell{X: 0, Y: 0}, golife.Cell{X: 2, Y: 1}, golife.Cell{X: 9, Y: 9})
+	pattern.Dying = DyingCells{golife.Cell{X: 1, Y: 1}: 2}
+	tests := []struct {
+		name     string
+		min, max golife.Cell
+		cellSize int
+		grid     bool
+		pixels   map[[2]int]color.NRGBA // pixel to expected color
+	}{
+		{"one pixel per cell", golife.Cell{X: 0, Y: 0}, golife.Cell{X: 2, Y: 1}, 1, false, map[[2]int]color.NRGBA{
+			{0, 0}: testLive, {1, 0}: testBackground, {2, 1}: testLive, {1, 1}: testDying,
+		}},
+		{"bigger cells", golife.Cell{X: 0, Y: 0}, golife.Cell{X: 2, Y: 1}, 10, false, map[[2]int]color.NRGBA{
+			{5, 5}: testLive, {15, 5}: testBackground, {25, 15}: testLive, {15, 15}: testDying, {10, 10}: testDying,
+		}},
+		{"offset region", golife.Cell{X: 1, Y: 1}, golife.Cell{X: 2, Y: 2}, 4, false, map[[2]int]color.NRGBA{
+			{1, 1}: testDying, {5, 1}: testLive, {1, 5}: testBackground,
+		}},
+		{"grid lines", golife.Cell{X: 0, Y: 0}, golife.Cell{X: 2, Y: 1}, 10, true, map[[2]int]color.NRGBA{
+			{10, 0}: testGrid, {15, 0}: testGrid, {10, 5}: testGrid, {15, 5}: testBackground, {5, 5}: testLive,
+		}},
+		{"no grid lines on tiny cells", golife.Cell{X: 0, Y: 0}, golife.Cell{X: 2, Y: 1}, 3, true, map[[2]int]color.NRGBA{
+			{3, 0}: testBackground, {4, 1}: testBackground,
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			at := renderPNG(t, newTestExport(test.min, test.max, test.cellSize, test.grid), pattern)
+			for pixel, want := range test.pixels {
+				if got := at(pixel[0], pixel[1]); got != want {
+					t.Errorf("pixel %v is %v, want %v", pixel, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderGlyphStyles(t *testing.T) {
+	pattern := newTestPattern(golife.Cell{X: 0, Y: 0})
+	for _, style := range []string{"Rectangle", "RoundedRectangle", "Circle"} {
+		ie := newTestExport(golife.Cell{X: 0, Y: 0}, golife.Cell{X: 0, Y: 0}, 20, false)
+		ie.GlyphStyle = style
+		at := renderPNG(t, ie, pattern)
+		if got := at(10, 10); got != testLive {
+			t.Errorf("%s: middle of the cell is %v", style, got)
+		}
+		wantCorner := testLive
+		if style != "Rectangle" {
+			wantCorner = testBackground
+		}
+		if got := at(1, 1); got != wantCorner {
+			t.Errorf("%s: corner of the cell is %v, want %v", style, got, wantCorner)
+		}
+	}
+}
+
+func TestRenderLimits(t *testing.T) {
+	pattern := newTestPattern(golife.Cell{X: 0, Y: 0})
+	tests := []struct {
+		name     string
+		min, max golife.Cell
+		cellSize int
+	}{
+		{"no pixels per cell", golife.Cell{X: 0, Y: 0}, golife.Cell{X: 1, Y: 1}, 0},
+		{"empty region", golife.Cell{X: 1, Y: 1}, golife.Cell{X: 0, Y: 0}, 1},
+		{"too many cells", golife.Cell{X: 0, Y: 0}, golife.Cell{X: maxExportImageSize, Y: 0}, 1},
+		{"too many pixels", golife.Cell{X: 0, Y: 0}, golife.Cell{X: maxExportImageSize / 10, Y: 0}, 11},
+		{"too big in all", golife.Cell{X: 0, Y: 0}, golife.Cell{X: maxExportImageSize - 1, Y: maxExportImageSize - 1}, 1},
+	}
+	for _, test := range tests {
+		if _, err := newTestExport(test.min, test.max, test.cellSize, false).Render(pattern); err == nil {
+			t.Errorf("%s: rendered without an error", test.name)
+		}
+	}
+}
+
+func TestWriteSVG(t *testing.T) {
+	pattern := newTestPattern(golife.Cell{X: 0, Y: 0}, golife.Cell{X: 2, Y: 1})
+	pattern.Dying = DyingCells{golife.Cell{X: 1, Y: 1}: 2}
+	for _, style := range []string{"Rectangle", "RoundedRectangle", "Circle"} {
+		for _, grid := range []bool{false, true} {
+			ie := newTestExport(golife.Cell{X: 0, Y: 0}, golife.Cell{X: 2, Y: 1}, 10, grid)
+			ie.GlyphStyle = style
+			var buf bytes.Buffer
+			if err := ie.WriteSVG(pattern, &buf); err != nil {
+				t.Fatal(err)
+			}
+			elements := make(map[string]int)
+			decoder := xml.NewDecoder(&buf)
+			for {
+				token, err := decoder.Token()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("%s: SVG doesn't parse: %v", style, err)
+				}
+				if start, ok := token.(xml.StartElement); ok {
+					elements[start.Name.Local]++
+				}
+			}
+			glyphs := elements["rect"] - 1 // the background
+			if style == "Circle" {
+				glyphs = elements["circle"]
+			}
+			if elements["svg"] != 1 || glyphs != 3 {
+				t.Errorf("%s: got elements %v, want 3 cells", style, elements)
+			}
+			if wantPaths := map[bool]int{false: 0, true: 1}[grid]; elements["path"] != wantPaths {
+				t.Errorf("%s: got %d grid paths, want %d", style, elements["path"], wantPaths)
+			}
+		}
+	}
+}
+
+func TestNewImageExportRegion(t *testing.T) {
+	InitConfig(test.NewApp())
+	sim := NewLifeSim(func() {})
+	sim.Game.AddCells([]golife.Cell{{X: -3, Y: 2}, {X: 7, Y: 4}})
+	sim.Dying = DyingCells{golife.Cell{X: 8, Y: -1}: 2}
+	sim.drawingSurface.Resize(fyne.NewSize(100, 60))
+	sim.Scale = 10
+	sim.BoxDisplayMin, sim.BoxDisplayMax = fyne.NewPos(-5, -3), fyne.NewPos(5, 3)
+
+	whole := sim.NewImageExport(true, 4, false)
+	if want := (golife.Cell{X: -3, Y: -1}); whole.Min != want {
+		t.Errorf("whole pattern starts at %v, want %v", whole.Min, want)
+	}
+	if want := (golife.Cell{X: 8, Y: 4}); whole.Max != want {
+		t.Errorf("whole pattern ends at %v, want %v", whole.Max, want)
+	}
+
+	// 10 by 6 cells around 0,0, plus any that are partly showing
+	view := sim.NewImageExport(false, 4, false)
+	if want := (golife.Cell{X: -5, Y: -3}); view.Min != want {
+		t.Errorf("view starts at %v, want %v", view.Min, want)
+	}
+	if want := (golife.Cell{X: 5, Y: 3}); view.Max != want {
+		t.Errorf("view ends at %v, want %v", view.Max, want)
+	}
+	if width, height := view.ImageSize(); width != 44 || height != 28 {
+		t.Errorf("view image is %dx%d, want 44x28", width, height)
+	}
+	at := renderPNG(t, view, sim.Pattern())
+	live := color.NRGBAModel.Convert(view.StateColors[1]).(color.NRGBA)
+	if got := at(2*4+2, 5*4+2); got != live { // the cell at -3,2
+		t.Errorf("live cell in view is %v, want %v", got, live)
+	}
+	if got := at(2, 2); got == live {
+		t.Error("empty cell in view is drawn as live")
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Jumping straight to a generation.  Going forward the sim is stepped as
+// fast as it can go without drawing anything, and with HashLife it takes
+// the biggest steps it can without going past.  Going back only works as
+// far as the history goes, or from a bookmark at or before the generation.
+
+const fastForwardCheckInterval = 100 * time.Millisecond
+
+// FastForward steps the sim on to the target generation, calling keepGoing
+// every so often with the generation it's got to, and stopping early if
+// that returns false.  A comparison is stepped along with it.
+func (ls *LifeSim) FastForward(target int, keepGoing func(generation int) bool) {
+	stepLog := ls.StepLog
+	defer func() { ls.StepLog = stepLog }()
+	lastCheck := time.Now()
+	for ls.Game.Generation < target {
+		if ls.UsingHashLife() {
+			ls.StepLog = min(maxHashLifeStepLog, bits.Len(uint(target-ls.Game.Generation))-1)
+		}
+		ls.Step()
+		if compare := ls.Compare; compare != nil {
+			compare.stepWith(ls)
+		}
+		if time.Since(lastCheck) >= fastForwardCheckInterval {
+			if !keepGoing(ls.Game.Generation) {
+				break
+			}
+			lastCheck = time.Now()
+		}
+	}
+	ls.Dirty = true
+}
+
+// rewindFor gets the sim to the latest generation it can that isn't past
+// the target, from the history or a bookmark, so the rest of the way is
+// forward.  Bookmarks from before the pattern was last changed are left
+// out, since going to one would bring back the old pattern, and so are
+// all of them while comparing.
+func (lc *LifeContainer) rewindFor(target int) error {
+	lc.Sim.SyncPopulation()
+	current := lc.Sim.Game.Generation
+	if target <= current && current-target <= lc.Control.StepsBack() {
+		for lc.Sim.Game.Generation > target && lc.Control.StepBackward() {
+		}
+		return nil
+	}
+
+	var best *Bookmark
+	for _, bookmark := range lc.Bookmarks {
+		generation := bookmark.Generation()
+		if !lc.IsComparing() && bookmark.IsCurrent(lc.Sim) && generation <= target && (best == nil || generation > best.Generation()) {
+			best = bookmark
+		}
+	}
+	if best != nil && (target < current || best.Generation() > current) {
+		return lc.GoToBookmark(best)
+	}
+	if target < current {
+		return fmt.Errorf("Generation %d is too far back.  Add a bookmark to be able to come back to a generation later.", target)
+	}
+	return nil
+}
+
+// ShowGoToGenerationDialog asks which generation to go to, then gets
+// there in the background with a progress dialog up.
+func ShowGoToGenerationDialog(lc *LifeContainer) {
+	generationEntry := widget.NewEntry()
+	generationEntry.SetText(strconv.Itoa(lc.Sim.Game.Generation))
+	generationEntry.Validator = func(text string) error {
+		generation, err := strconv.Atoi(text)
+		if err == nil && generation < 0 {
+			return errors.New("Generation can't be negative")
+		}
+		return err
+	}
+	formItems := []*widget.FormItem{widget.NewFormItem("Generation", generationEntry)}
+	dialog.ShowForm("Go to generation", "Go", "Cancel", formItems, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		target, _ := strconv.Atoi(generationEntry.Text)
+		lc.Control.StopSim()
+		if err := lc.rewindFor(target); err != nil {
+			dialog.ShowError(err, mainWindow)
+			return
+		}
+		start := lc.Sim.Game.Generation
+		if start >= target {
+			lc.Sim.Dirty = true
+			return
+		}
+
+		var canceled atomic.Bool
+		progressBar := widget.NewProgressBar()
+		progress := dialog.NewCustom(fmt.Sprintf("Going to generation %d", target), "Cancel",
+			container.NewPadded(progressBar), mainWindow)
+		progress.SetOnClosed(func() { canceled.Store(true) })
+		progress.Show()
+
+		go func() {
+			lc.Sim.FastForward(target, func(generation int) bool {
+				fyne.Do(func() { progressBar.SetValue(float64(generation-start) / float64(target-start)) })
+				return !canceled.Load()
+			})
+			fyne.Do(func() {
+				progress.Hide()
+				lc.Control.UpdateBackButton()
+			})
+		}()
+	}, mainWindow)
+}
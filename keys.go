@@ -0,0 +1,440 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Keyboard shortcuts.  Every action that can be bound to a key is listed
+// in keyActions, and each has two slots, so it can have a shortcut and an
+// alternate.  Only bindings the user has changed are kept in the
+// preferences.
+//
+// Bindings with Control, Alt or Super are shortcuts, and go on the action's
+// menu item if it has one, or on the canvas if it doesn't.  Plain keys
+// (the arrows, say) are handled when the canvas gets a typed key.
+
+const keyBindingSlots = 2
+
+type KeySlots [keyBindingSlots]KeyBinding
+
+type KeyBinding struct {
+	Key      fyne.KeyName
+	Modifier fyne.KeyModifier
+}
+
+var keyModifierNames = []struct {
+	modifier fyne.KeyModifier
+	name     string
+}{
+	{fyne.KeyModifierControl, "Control"},
+	{fyne.KeyModifierAlt, "Alt"},
+	{fyne.KeyModifierShift, "Shift"},
+	{fyne.KeyModifierSuper, "Super"},
+}
+
+// String is how the binding is stored, e.g. "Control+Shift+Z", or "" if unbound
+func (kb KeyBinding) String() string {
+	if kb.Key == fyne.KeyUnknown {
+		return ""
+	}
+	var parts []string
+	for _, mod := range keyModifierNames {
+		if kb.Modifier&mod.modifier != 0 {
+			parts = append(parts, mod.name)
+		}
+	}
+	return strings.Join(append(parts, string(kb.Key)), "+")
+}
+
+// Label is how the binding is shown to the user
+func (kb KeyBinding) Label() string {
+	if kb.Key == fyne.KeyUnknown {
+		return "none"
+	}
+	label := kb.String()
+	if runtime.GOOS == "darwin" {
+		label = strings.ReplaceAll(label, "Super", "Cmd")
+	}
+	return label
+}
+
+func ParseKeyBinding(str string) (KeyBinding, error) {
+	var kb KeyBinding
+	if str == "" {
+		return kb, nil
+	}
+	for {
+		found := false
+		for _, mod := range keyModifierNames {
+			if rest, ok := strings.CutPrefix(str, mod.name+"+"); ok && rest != "" {
+				kb.Modifier |= mod.modifier
+				str, found = rest, true
+			}
+		}
+		if !found {
+			break
+		}
+	}
+	if strings.Contains(str, "+") && str != "+" {
+		return kb, fmt.Errorf("Unknown key binding %s", str)
+	}
+	kb.Key = fyne.KeyName(str)
+	return kb, nil
+}
+
+// IsShortcut is true for bindings that the driver sends as shortcuts
+// rather than typed keys.  Shift alone isn't enough for that.
+func (kb KeyBinding) IsShortcut() bool {
+	return kb.Modifier&^fyne.KeyModifierShift != 0
+}
+
+func (kb KeyBinding) Shortcut() fyne.Shortcut {
+	if kb.Key == fyne.KeyUnknown || !kb.IsShortcut() {
+		return nil
+	}
+	return &desktop.CustomShortcut{KeyName: kb.Key, Modifier: kb.Modifier}
+}
+
+type keyAction struct {
+	id       string
+	name     string
+	defaults KeySlots
+}
+
+func bind(key fyne.KeyName, mod fyne.KeyModifier) KeyBinding {
+	return KeyBinding{Key: key, Modifier: mod}
+}
+
+const modKey = fyne.KeyModifierShortcutDefault // Command on the Mac, Control everywhere else
+
+var keyActions = []keyAction{
+	{"new_tab", "New tab", KeySlots{bind(fyne.KeyN, modKey)}},
+	{"close_tab", "Close tab", KeySlots{bind(fyne.KeyW, modKey)}},
+	{"get_info", "Get info", KeySlots{bind(fyne.KeyI, modKey)}},
+	{"settings", "Settings", KeySlots{bind(fyne.KeySemicolon, modKey)}},
+	{"run_stop", "Run/Stop", KeySlots{bind(fyne.KeyR, modKey), bind(fyne.KeyR, 0)}},
+	{"run_all", "Run all tabs", KeySlots{}},
+	{"pause_all", "Pause all tabs", KeySlots{}},
+	{"keep_running", "Keep running when hidden", KeySlots{}},
+	{"step_forward", "Step forward", KeySlots{bind(fyne.KeyPeriod, 0)}},
+	{"step_backward", "Step back", KeySlots{bind(fyne.KeyComma, 0)}},
+	{"goto_generation", "Go to generation", KeySlots{bind(fyne.KeyG, modKey)}},
+	{"add_bookmark", "Add bookmark", KeySlots{bind(fyne.KeyB, modKey)}},
+	{"random_fill", "Random fill", KeySlots{}},
+	{"faster", "Faster", KeySlots{bind(fyne.KeyRightBracket, 0)}},
+	{"slower", "Slower", KeySlots{bind(fyne.KeyLeftBracket, 0)}},
+	{"zoom_in", "Zoom in", KeySlots{bind(fyne.KeyEqual, 0)}},
+	{"zoom_out", "Zoom out", KeySlots{bind(fyne.KeyMinus, 0)}},
+	{"zoom_fit", "Zoom to fit", KeySlots{bind(fyne.KeyF, modKey)}},
+	{"auto_zoom", "Auto zoom", KeySlots{bind(fyne.KeyA, modKey)}},
+	{"show_grid", "Show grid", KeySlots{}},
+	{"show_rulers", "Show rulers", KeySlots{}},
+	{"edit_mode", "Edit mode", KeySlots{bind(fyne.KeyE, modKey)}},
+	{"pan_up", "Pan up", KeySlots{bind(fyne.KeyUp, 0)}},
+	{"pan_down", "Pan down", KeySlots{bind(fyne.KeyDown, 0)}},
+	{"pan_left", "Pan left", KeySlots{bind(fyne.KeyLeft, 0)}},
+	{"pan_right", "Pan right", KeySlots{bind(fyne.KeyRight, 0)}},
+	{"undo", "Undo", KeySlots{bind(fyne.KeyZ, modKey)}},
+	{"redo", "Redo", KeySlots{bind(fyne.KeyZ, modKey|fyne.KeyModifierShift)}},
+	{"copy", "Copy", KeySlots{bind(fyne.KeyC, modKey)}},
+	{"cut", "Cut", KeySlots{bind(fyne.KeyX, modKey)}},
+	{"paste", "Paste", KeySlots{bind(fyne.KeyV, modKey)}},
+	{"rotate_cw", "Rotate clockwise", KeySlots{bind(fyne.KeyRightBracket, modKey)}},
+	{"rotate_ccw", "Rotate counterclockwise", KeySlots{bind(fyne.KeyLeftBracket, modKey)}},
+	{"flip_horizontal", "Flip horizontal", KeySlots{bind(fyne.KeyH, modKey|fyne.KeyModifierShift)}},
+	{"flip_vertical", "Flip vertical", KeySlots{bind(fyne.KeyV, modKey|fyne.KeyModifierShift)}},
+	{"nudge_up", "Nudge up", KeySlots{bind(fyne.KeyUp, modKey|fyne.KeyModifierShift)}},
+	{"nudge_down", "Nudge down", KeySlots{bind(fyne.KeyDown, modKey|fyne.KeyModifierShift)}},
+	{"nudge_left", "Nudge left", KeySlots{bind(fyne.KeyLeft, modKey|fyne.KeyModifierShift)}},
+	{"nudge_right", "Nudge right", KeySlots{bind(fyne.KeyRight, modKey|fyne.KeyModifierShift)}},
+}
+
+func findKeyAction(id string) *keyAction {
+	for index := range keyActions {
+		if keyActions[index].id == id {
+			return &keyActions[index]
+		}
+	}
+	return nil
+}
+
+func (c ConfigT) KeyBindings(id string) KeySlots {
+	action := findKeyAction(id)
+	if action == nil {
+		return KeySlots{}
+	}
+	strs := c.app.Preferences().StringListWithFallback(keyBindingKeyPrefix+id, nil)
+	if strs == nil {
+		return action.defaults
+	}
+	var bindings KeySlots
+	for slot := 0; slot < min(len(strs), keyBindingSlots); slot++ {
+		binding, err := ParseKeyBinding(strs[slot])
+		if err != nil {
+			fyne.LogError("Unable to read key binding for "+id, err)
+			return action.defaults
+		}
+		bindings[slot] = binding
+	}
+	return bindings
+}
+
+// SetKeyBindings stores the bindings for the action, forgetting them if
+// they're the defaults so that changes to the defaults get picked up.
+func (c ConfigT) SetKeyBindings(id string, bindings KeySlots) {
+	action := findKeyAction(id)
+	if action == nil {
+		return
+	}
+	if bindings == action.defaults {
+		c.app.Preferences().RemoveValue(keyBindingKeyPrefix + id)
+		return
+	}
+	strs := make([]string, keyBindingSlots)
+	for slot, binding := range bindings {
+		strs[slot] = binding.String()
+	}
+	c.app.Preferences().SetStringList(keyBindingKeyPrefix+id, strs)
+}
+
+// KeyBindings connects the bindings in Config to the actions
+type KeyBindings struct {
+	window     fyne.Window
+	menu       *fyne.MainMenu
+	actions    map[string]func()
+	menuItems  map[string]*fyne.MenuItem
+	registered []fyne.Shortcut // canvas shortcuts we've added, to remove when they change
+	plainKeys  map[fyne.KeyName]func()
+}
+
+var keyBindings *KeyBindings
+
+func NewKeyBindings(window fyne.Window) *KeyBindings {
+	return &KeyBindings{window: window, actions: make(map[string]func()),
+		menuItems: make(map[string]*fyne.MenuItem), plainKeys: make(map[fyne.KeyName]func())}
+}
+
+// Handle sets the function to call for an action
+func (keys *KeyBindings) Handle(id string, action func()) {
+	keys.actions[id] = action
+}
+
+// HandleMenuItem makes the menu item's action the action, and shows its
+// shortcut on the menu item.
+func (keys *KeyBindings) HandleMenuItem(id string, item *fyne.MenuItem) {
+	keys.actions[id] = item.Action
+	keys.menuItems[id] = item
+}
+
+// Apply puts the current bindings into effect
+func (keys *KeyBindings) Apply() {
+	for _, shortcut := range keys.registered {
+		keys.window.Canvas().RemoveShortcut(shortcut)
+	}
+	keys.registered = keys.registered[:0]
+	keys.plainKeys = make(map[fyne.KeyName]func())
+
+	for _, action := range keyActions {
+		handler, found := keys.actions[action.id]
+		if !found {
+			continue
+		}
+		item := keys.menuItems[action.id]
+		if item != nil {
+			item.Shortcut = nil
+		}
+		for _, binding := range Config.KeyBindings(action.id) {
+			switch {
+			case binding.Key == fyne.KeyUnknown:
+			case !binding.IsShortcut():
+				keys.plainKeys[binding.Key] = handler
+			case item != nil && item.Shortcut == nil:
+				item.Shortcut = binding.Shortcut()
+			default:
+				shortcut := binding.Shortcut()
+				keys.window.Canvas().AddShortcut(shortcut, func(_ fyne.Shortcut) {
+					handler()
+				})
+				keys.registered = append(keys.registered, shortcut)
+			}
+		}
+	}
+	if keys.menu != nil {
+		keys.menu.Refresh()
+	}
+}
+
+// SetMainMenu tells us the menu to refresh when the shortcuts change
+func (keys *KeyBindings) SetMainMenu(menu *fyne.MainMenu) {
+	keys.menu = menu
+}
+
+// TypedKey runs the action bound to a plain key, returning false if there isn't one
+func (keys *KeyBindings) TypedKey(keyEvent *fyne.KeyEvent) bool {
+	handler, found := keys.plainKeys[keyEvent.Name]
+	if found {
+		handler()
+	}
+	return found
+}
+
+// keyCapture is a button that, once tapped, takes the next key pressed as
+// its binding.
+type keyCapture struct {
+	widget.Button
+	binding   KeyBinding
+	capturing bool
+	onChanged func(KeyBinding)
+}
+
+func newKeyCapture(binding KeyBinding, onChanged func(KeyBinding)) *keyCapture {
+	kc := &keyCapture{binding: binding, onChanged: onChanged}
+	kc.Text = binding.Label()
+	kc.ExtendBaseWidget(kc)
+	return kc
+}
+
+func (kc *keyCapture) SetBinding(binding KeyBinding) {
+	kc.binding = binding
+	kc.capturing = false
+	kc.SetText(binding.Label())
+}
+
+func (kc *keyCapture) Tapped(_ *fyne.PointEvent) {
+	kc.capturing = true
+	kc.SetText("Press a key...")
+	if c := fyne.CurrentApp().Driver().CanvasForObject(kc); c != nil {
+		c.Focus(kc)
+	}
+}
+
+func (kc *keyCapture) FocusLost() {
+	if kc.capturing {
+		kc.SetBinding(kc.binding)
+	}
+	kc.Button.FocusLost()
+}
+
+func (kc *keyCapture) captured(binding KeyBinding) {
+	kc.capturing = false
+	kc.SetText(kc.binding.Label())
+	if kc.onChanged != nil {
+		kc.onChanged(binding)
+	}
+}
+
+func (kc *keyCapture) TypedKey(keyEvent *fyne.KeyEvent) {
+	if !kc.capturing {
+		kc.Button.TypedKey(keyEvent)
+		return
+	}
+	switch keyEvent.Name {
+	case fyne.KeyEscape:
+		kc.SetBinding(kc.binding)
+	case fyne.KeyBackspace:
+		kc.captured(KeyBinding{})
+	default:
+		kc.captured(KeyBinding{Key: keyEvent.Name})
+	}
+}
+
+func (kc *keyCapture) TypedShortcut(shortcut fyne.Shortcut) {
+	if !kc.capturing {
+		return
+	}
+	if keyed, ok := shortcut.(fyne.KeyboardShortcut); ok {
+		kc.captured(KeyBinding{Key: keyed.Key(), Modifier: keyed.Mod()})
+	}
+}
+
+// ShowKeyBindingsDialog lets the user rebind the actions.  Binding a key
+// that's already in use asks before taking it away from the other action.
+func ShowKeyBindingsDialog(keys *KeyBindings) {
+	bindings := make(map[string]KeySlots, len(keyActions))
+	captures := make(map[string][keyBindingSlots]*keyCapture, len(keyActions))
+
+	// conflict finds the action and slot already using the binding
+	conflict := func(binding KeyBinding, skipID string, skipSlot int) (*keyAction, int) {
+		for index := range keyActions {
+			action := &keyActions[index]
+			for slot, other := range bindings[action.id] {
+				if other == binding && (action.id != skipID || slot != skipSlot) {
+					return action, slot
+				}
+			}
+		}
+		return nil, 0
+	}
+
+	grid := container.New(layout.NewGridLayout(3))
+	for _, action := range keyActions {
+		bindings[action.id] = Config.KeyBindings(action.id)
+		var slotCaptures [keyBindingSlots]*keyCapture
+		for slot := range slotCaptures {
+			slotCaptures[slot] = newKeyCapture(bindings[action.id][slot], func(binding KeyBinding) {
+				set := func() {
+					actionBindings := bindings[action.id]
+					actionBindings[slot] = binding
+					bindings[action.id] = actionBindings
+					captures[action.id][slot].SetBinding(binding)
+				}
+				if binding.Key == fyne.KeyUnknown {
+					set()
+					return
+				}
+				other, otherSlot := conflict(binding, action.id, slot)
+				if other == nil {
+					set()
+					return
+				}
+				dialog.ShowConfirm("Shortcut in use",
+					fmt.Sprintf("%s is already used for %s.  Use it for %s instead?", binding.Label(), other.name, action.name),
+					func(yes bool) {
+						if yes {
+							otherBindings := bindings[other.id]
+							otherBindings[otherSlot] = KeyBinding{}
+							bindings[other.id] = otherBindings
+							captures[other.id][otherSlot].SetBinding(KeyBinding{})
+							set()
+						}
+					}, mainWindow)
+			})
+		}
+		captures[action.id] = slotCaptures
+		grid.Add(widget.NewLabel(action.name))
+		grid.Add(slotCaptures[0])
+		grid.Add(slotCaptures[1])
+	}
+
+	resetButton := widget.NewButton("Reset to defaults", func() {
+		for _, action := range keyActions {
+			bindings[action.id] = action.defaults
+			for slot, capture := range captures[action.id] {
+				capture.SetBinding(action.defaults[slot])
+			}
+		}
+	})
+	help := widget.NewLabel("Click a shortcut and press the new keys.  Escape cancels, Backspace clears it.")
+	scroll := container.NewVScroll(grid)
+	scroll.SetMinSize(fyne.NewSize(520, 400))
+	content := container.NewBorder(help, resetButton, nil, nil, scroll)
+
+	dialog.ShowCustomConfirm("Keyboard Shortcuts", "Save", "Cancel", content, func(save bool) {
+		if !save {
+			return
+		}
+		for _, action := range keyActions {
+			Config.SetKeyBindings(action.id, bindings[action.id])
+		}
+		keys.Apply()
+	}, mainWindow)
+}
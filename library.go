@@ -0,0 +1,291 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+
+	"github.com/pneumaticdeath/golife"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// The Library is a window for managing the saved games, which the Load
+// menu can only pick from.
+
+const (
+	thumbnailSize     = 64  // pixels on a side
+	thumbnailMaxCells = 256 // patterns bigger than this only show their upper left corner
+)
+
+type Library struct {
+	window     fyne.Window
+	store      *SavedGameStore
+	names      []string // the names matching the search, sorted
+	selected   string
+	search     *widget.Entry
+	list       *widget.List
+	thumbnails map[string]image.Image
+	open       func(*Pattern) // opens a pattern in a new tab
+	changed    func()         // called when the saved games have changed
+}
+
+var openLibrary *Library
+
+// ShowLibrary opens the library window, or brings it to the front if it's
+// already open.
+func ShowLibrary(open func(*Pattern), changed func()) {
+	if openLibrary != nil {
+		openLibrary.Reload()
+		openLibrary.window.RequestFocus()
+		return
+	}
+
+	lib := &Library{open: open, changed: changed}
+	lib.window = fyne.CurrentApp().NewWindow("Library")
+
+	lib.search = widget.NewEntry()
+	lib.search.SetPlaceHolder("Search")
+	lib.search.OnChanged = func(_ string) {
+		lib.filter()
+	}
+
+	lib.list = widget.NewList(func() int {
+		return len(lib.names)
+	}, func() fyne.CanvasObject {
+		thumbnail := canvas.NewImageFromImage(nil)
+		thumbnail.FillMode = canvas.ImageFillContain
+		thumbnail.ScaleMode = canvas.ImageScalePixels
+		thumbnail.SetMinSize(fyne.NewSize(thumbnailSize, thumbnailSize))
+		name := widget.NewLabel("")
+		name.TextStyle = fyne.TextStyle{Bold: true}
+		return container.New(layout.NewHBoxLayout(), thumbnail,
+			container.New(layout.NewVBoxLayout(), name, widget.NewLabel("")))
+	}, func(id widget.ListItemID, obj fyne.CanvasObject) {
+		if id >= len(lib.names) {
+			return
+		}
+		name := lib.names[id]
+		row := obj.(*fyne.Container)
+		thumbnail := row.Objects[0].(*canvas.Image)
+		thumbnail.Image = lib.thumbnail(name)
+		thumbnail.Refresh()
+		labels := row.Objects[1].(*fyne.Container)
+		labels.Objects[0].(*widget.Label).SetText(name)
+		labels.Objects[1].(*widget.Label).SetText(savedGameInfo(lib.store.Get(name)))
+	})
+	lib.list.OnSelected = func(id widget.ListItemID) {
+		if id < len(lib.names) {
+			lib.selected = lib.names[id]
+		}
+	}
+	lib.list.OnUnselected = func(_ widget.ListItemID) {
+		lib.selected = ""
+	}
+
+	openButton := widget.NewButtonWithIcon("Open", theme.FolderOpenIcon(), lib.openSelected)
+	renameButton := widget.NewButtonWithIcon("Rename", theme.DocumentCreateIcon(), lib.renameSelected)
+	duplicateButton := widget.NewButtonWithIcon("Duplicate", theme.ContentCopyIcon(), lib.duplicateSelected)
+	deleteButton := widget.NewButtonWithIcon("Delete", theme.DeleteIcon(), lib.deleteSelected)
+	exportButton := widget.NewButtonWithIcon("Export", theme.DocumentSaveIcon(), lib.exportSelected)
+	buttons := container.New(layout.NewHBoxLayout(), openButton, renameButton, duplicateButton,
+		deleteButton, exportButton)
+
+	lib.window.SetContent(container.NewBorder(lib.search, buttons, nil, nil, lib.list))
+	lib.window.SetOnClosed(func() {
+		openLibrary = nil
+	})
+	lib.Reload()
+
+	openLibrary = lib
+	lib.window.Resize(fyne.NewSize(480, 600))
+	lib.window.Show()
+}
+
+func savedGameInfo(saved *SavedGame) string {
+	info := fmt.Sprintf("%d cells", saved.Cells)
+	if saved.Saved.IsZero() {
+		return info
+	}
+	return info + ", saved " + saved.Saved.Format("2006-01-02 15:04")
+}
+
+// Reload refreshes the list, for when the games have changed elsewhere
+func (lib *Library) Reload() {
+	lib.store = Config.SavedGames()
+	lib.thumbnails = make(map[string]image.Image)
+	lib.filter()
+}
+
+// filter updates the list to the games whose names contain the search text
+func (lib *Library) filter() {
+	search := strings.ToLower(strings.TrimSpace(lib.search.Text))
+	lib.names = lib.names[:0]
+	for _, name := range lib.store.Names() {
+		if strings.Contains(strings.ToLower(name), search) {
+			lib.names = append(lib.names, name)
+		}
+	}
+	lib.list.UnselectAll()
+	lib.selected = ""
+	lib.list.Refresh()
+}
+
+// changedStore updates the list after a change to the store and lets the
+// rest of the app know, or tells the user why the change failed
+func (lib *Library) changedStore(err error) {
+	if err != nil {
+		dialog.ShowError(err, lib.window)
+	}
+	lib.thumbnails = make(map[string]image.Image)
+	lib.filter()
+	if lib.changed != nil {
+		lib.changed()
+	}
+}
+
+func (lib *Library) thumbnail(name string) image.Image {
+	if img, found := lib.thumbnails[name]; found {
+		return img
+	}
+	pattern, err := lib.store.Peek(name) // not Load, so the library doesn't keep every game in memory
+	if err != nil {
+		fyne.LogError("Unable to load "+name, err)
+		return nil
+	}
+	img, err := renderThumbnail(pattern)
+	if err != nil {
+		fyne.LogError("Unable to render thumbnail for "+name, err)
+		return nil
+	}
+	lib.thumbnails[name] = img
+	return img
+}
+
+// renderThumbnail draws a small picture of the pattern in the paused
+// colors, for lists of patterns.
+func renderThumbnail(pattern *Pattern) (image.Image, error) {
+	ie := &ImageExport{GlyphStyle: "Rectangle", Background: Config.BackgroundColor()}
+	cellColor := Config.PausedCellColor()
+	ie.StateColors = make([]color.Color, max(pattern.Rule.States, 2))
+	for state := range ie.StateColors {
+		ie.StateColors[state] = cellColor
+		if state > 1 {
+			ie.StateColors[state] = blendColors(cellColor, ie.Background, float64(state-1)/float64(pattern.Rule.States))
+		}
+	}
+	if pattern.Game.Size() > 0 || len(pattern.Dying) > 0 {
+		ie.Min, ie.Max = PatternBoundingBox(pattern)
+	} // otherwise there's nothing to draw, so just one cell of background
+	ie.Max = golife.Cell{X: min(ie.Max.X, ie.Min.X+thumbnailMaxCells-1), Y: min(ie.Max.Y, ie.Min.Y+thumbnailMaxCells-1)}
+	span := max(ie.Max.X-ie.Min.X+1, ie.Max.Y-ie.Min.Y+1)
+	ie.CellSize = max(1, thumbnailSize/int(span))
+	return ie.Render(pattern)
+}
+
+// hasSelection returns whether a game is selected, telling the user if
+// there isn't one
+func (lib *Library) hasSelection() bool {
+	if lib.store.Get(lib.selected) == nil {
+		dialog.ShowInformation("Nothing selected", "Select a saved game first.", lib.window)
+		return false
+	}
+	return true
+}
+
+func (lib *Library) openSelected() {
+	if !lib.hasSelection() {
+		return
+	}
+	pattern, err := lib.store.Load(lib.selected)
+	if err != nil {
+		dialog.ShowError(err, lib.window)
+		return
+	}
+	lib.open(pattern)
+}
+
+// uniqueName adds a number to the name if there's already a game called that
+func (lib *Library) uniqueName(name string) string {
+	unique := name
+	for counter := 2; ; counter++ {
+		if lib.store.Get(unique) == nil {
+			return unique
+		}
+		unique = fmt.Sprintf("%s %d", name, counter)
+	}
+}
+
+func (lib *Library) renameSelected() {
+	if !lib.hasSelection() {
+		return
+	}
+	oldName := lib.selected
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText(oldName)
+	formItems := []*widget.FormItem{widget.NewFormItem("Name:", nameEntry)}
+	dialog.ShowForm("Rename "+oldName, "Rename", "Cancel", formItems, func(rename bool) {
+		newName := strings.TrimSpace(nameEntry.Text)
+		if !rename || newName == "" || newName == oldName {
+			return
+		}
+		lib.changedStore(lib.store.Rename(oldName, newName))
+	}, lib.window)
+}
+
+func (lib *Library) duplicateSelected() {
+	if !lib.hasSelection() {
+		return
+	}
+	lib.changedStore(lib.store.Duplicate(lib.selected, lib.uniqueName(lib.selected+" copy")))
+}
+
+func (lib *Library) deleteSelected() {
+	if !lib.hasSelection() {
+		return
+	}
+	name := lib.selected
+	dialog.ShowConfirm("Delete "+name, fmt.Sprintf("Are you sure you want to delete %s?", name), func(yes bool) {
+		if yes {
+			lib.changedStore(lib.store.Delete(name))
+		}
+	}, lib.window)
+}
+
+func (lib *Library) exportSelected() {
+	if !lib.hasSelection() {
+		return
+	}
+	pattern, err := lib.store.Load(lib.selected)
+	if err != nil {
+		dialog.ShowError(err, lib.window)
+		return
+	}
+	fileSave := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, lib.window)
+			return
+		}
+		if writer == nil {
+			return
+		}
+		defer writer.Close()
+		if err := FindPatternWriter(writer.URI().Name())(pattern, writer); err != nil {
+			dialog.ShowError(err, lib.window)
+		}
+		if writer.URI().Scheme() == "file" {
+			Config.SetLastUsedDirURI(writer.URI())
+		}
+	}, lib.window)
+	fileSave.SetFilter(&LongExtensionsFileFilter{Extensions: []string{".rle", ".rle.txt", ".mc", ".mc.gz"}})
+	fileSave.SetFileName(strings.ReplaceAll(lib.selected, " ", "_") + ".rle")
+	fileSave.SetLocation(Config.LastUsedDirURI())
+	fileSave.Show()
+}
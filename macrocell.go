@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pneumaticdeath/golife"
+)
+
+// Macrocell is the format Golly uses for huge patterns.  It is a dump of
+// the HashLife quadtree: each line after the header is a node, numbered
+// from 1 in the order they appear, and the last one is the root.  Nodes
+// that are 8x8 cells are written out as a little bitmap ("." dead, "*"
+// alive and "$" at the end of each row, with trailing dead cells and rows
+// left off), and the bigger ones as "level nw ne sw se", where the
+// children are node numbers or 0 for an empty quadrant.  Like our HashLife
+// universe, the root is centered on the origin.  Files are often
+// gzipped, so the reader unzips them if it needs to.
+
+const macrocellHeader = "[M2] (GooeyLife)"
+
+// ReadMacrocell reads a pattern in Macrocell format, which may be gzipped.
+func ReadMacrocell(reader io.Reader) (*Pattern, error) {
+	buffered := bufio.NewReader(reader)
+	magic, _ := buffered.Peek(2)
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		unzipped, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		defer unzipped.Close()
+		buffered = bufio.NewReader(unzipped)
+	}
+
+	pattern := &Pattern{Game: golife.NewGame(), Rule: ConwayRule}
+	game := pattern.Game
+	h := NewHashLife(ConwayRule)
+	nodes := []*hlNode{nil} // node 0 is always empty, and the level depends on the parent
+
+	scanner := bufio.NewScanner(buffered)
+	scanner.Buffer(make([]byte, 0, 4096), 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		lineNumber++
+		switch {
+		case lineNumber == 1:
+			if !strings.HasPrefix(line, "[M2]") {
+				return nil, errors.New("Not a Macrocell file")
+			}
+		case line == "":
+		case strings.HasPrefix(line, "#R"):
+			rule, err := ParseRule(strings.TrimPrefix(line, "#R"))
+			if err != nil {
+				return nil, err
+			}
+			if rule.IsGenerations() {
+				return nil, errors.New("Macrocell files with Generations rules aren't supported")
+			}
+			pattern.Rule = rule
+		case strings.HasPrefix(line, "#G"):
+			generation, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "#G")))
+			if err != nil {
+				return nil, fmt.Errorf("Bad generation in line %d of Macrocell file", lineNumber)
+			}
+			game.Generation = generation
+		case strings.HasPrefix(line, "#N "):
+			game.Name = strings.TrimSpace(strings.TrimPrefix(line, "#N "))
+		case strings.HasPrefix(line, "#O "):
+			game.Author = strings.TrimSpace(strings.TrimPrefix(line, "#O "))
+		case strings.HasPrefix(line, "#"):
+			game.Comments = append(game.Comments, strings.TrimPrefix(line, "#"))
+		case strings.ContainsAny(line[:1], ".*$"):
+			leaf, err := readMacrocellLeaf(h, line)
+			if err != nil {
+				return nil, fmt.Errorf("%v in line %d of Macrocell file", err, lineNumber)
+			}
+			nodes = append(nodes, leaf)
+		default:
+			node, err := readMacrocellNode(h, nodes, line)
+			if err != nil {
+				return nil, fmt.Errorf("%v in line %d of Macrocell file", err, lineNumber)
+			}
+			nodes = append(nodes, node)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(nodes) > 1 {
+		h.root = nodes[len(nodes)-1]
+		game.Population = h.Population()
+	}
+	return pattern, nil
+}
+
+func readMacrocellLeaf(h *HashLife, line string) (*hlNode, error) {
+	var cells []golife.Cell
+	var x, y golife.Coord
+	for _, c := range line {
+		switch c {
+		case '.':
+			x++
+		case '*':
+			cells = append(cells, golife.Cell{X: x, Y: y})
+			x++
+		case '$':
+			x, y = 0, y+1
+		default:
+			return nil, fmt.Errorf("Got unknown character %c", c)
+		}
+		if x > 8 || y > 8 {
+			return nil, errors.New("Leaf node is bigger than 8x8")
+		}
+	}
+	return h.build(cells, 0, 0, 3), nil
+}
+
+func readMacrocellNode(h *HashLife, nodes []*hlNode, line string) (*hlNode, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 5 {
+		return nil, errors.New("Expected a level and four children")
+	}
+	level, err := strconv.Atoi(fields[0])
+	if err != nil || level < 4 || level > 62 {
+		return nil, errors.New("Bad node level")
+	}
+	var children [4]*hlNode
+	for index := range children {
+		childIndex, err := strconv.Atoi(fields[index+1])
+		if err != nil || childIndex < 0 || childIndex >= len(nodes) {
+			return nil, errors.New("Bad child node")
+		}
+		child := nodes[childIndex]
+		if child == nil {
+			child = h.emptyNode(uint8(level - 1))
+		}
+		if int(child.level) != level-1 {
+			return nil, errors.New("Child node is the wrong size")
+		}
+		children[index] = child
+	}
+	return h.join(children[0], children[1], children[2], children[3]), nil
+}
+
+// WriteMacrocell writes the pattern in Macrocell format.
+func WriteMacrocell(pattern *Pattern, writer io.Writer) error {
+	if pattern.Rule.IsGenerations() {
+		return errors.New("Macrocell files can only be written for two state rules")
+	}
+	game := pattern.Game
+	h := NewHashLife(pattern.Rule)
+	h.SetPopulation(game.Population)
+
+	out := bufio.NewWriter(writer)
+	fmt.Fprintln(out, macrocellHeader)
+	fmt.Fprintf(out, "#R %s\n", pattern.Rule)
+	if game.Generation > 0 {
+		fmt.Fprintf(out, "#G %d\n", game.Generation)
+	}
+	if game.Name != "" {
+		fmt.Fprintf(out, "#N %s\n", game.Name)
+	}
+	if game.Author != "" {
+		fmt.Fprintf(out, "#O %s\n", game.Author)
+	}
+	for _, comment := range game.Comments {
+		fmt.Fprintf(out, "#%s\n", strings.TrimSuffix(strings.TrimPrefix(comment, "#"), "\n"))
+	}
+
+	numbers := make(map[*hlNode]int)
+	var write func(node *hlNode) int
+	write = func(node *hlNode) int {
+		if node.population == 0 {
+			return 0
+		}
+		if number, found := numbers[node]; found {
+			return number
+		}
+		if node.level == 3 {
+			writeMacrocellLeaf(out, h, node)
+		} else {
+			nw, ne, sw, se := write(node.nw), write(node.ne), write(node.sw), write(node.se)
+			fmt.Fprintf(out, "%d %d %d %d %d\n", node.level, nw, ne, sw, se)
+		}
+		numbers[node] = len(numbers) + 1
+		return numbers[node]
+	}
+	if h.root.population > 0 {
+		write(h.root)
+	}
+
+	return out.Flush()
+}
+
+func writeMacrocellLeaf(out *bufio.Writer, h *HashLife, node *hlNode) {
+	var rows [8][8]bool
+	h.visit(node, 0, 0, golife.Cell{X: 0, Y: 0}, golife.Cell{X: 7, Y: 7}, 1, func(x, y, _ golife.Coord) {
+		rows[y][x] = true
+	})
+	var line strings.Builder
+	pendingRows := 0
+	for _, row := range rows {
+		last := -1
+		for x, alive := range row {
+			if alive {
+				last = x
+			}
+		}
+		if last < 0 {
+			pendingRows++
+			continue
+		}
+		line.WriteString(strings.Repeat("$", pendingRows))
+		pendingRows = 0
+		for x := 0; x <= last; x++ {
+			if row[x] {
+				line.WriteByte('*')
+			} else {
+				line.WriteByte('.')
+			}
+		}
+		line.WriteByte('$')
+	}
+	fmt.Fprintln(out, line.String())
+}
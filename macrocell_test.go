@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pneumaticdeath/golife"
+)
+
+// a glider as Golly writes it: one leaf in the lower left quadrant of a
+// 16x16 root, so it sits just left of the origin
+const gollyGliderMacrocell = `[M2] (golly 4.2)
+#R B3/S23
+.*$..*$***$
+4 0 0 1 0
+`
+
+func TestReadGollyMacrocell(t *testing.T) {
+	pattern, err := ReadMacrocell(strings.NewReader(gollyGliderMacrocell))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := sortedCells([]golife.Cell{{X: -7, Y: 0}, {X: -6, Y: 1}, {X: -8, Y: 2}, {X: -7, Y: 2}, {X: -6, Y: 2}})
+	if got := populationCells(pattern.Game.Population); !slices.Equal(got, want) {
+		t.Errorf("got cells %v, want %v", got, want)
+	}
+	if !pattern.Rule.IsConway() {
+		t.Errorf("got rule %s", pattern.Rule)
+	}
+
+	// the same again, gzipped
+	var zipped bytes.Buffer
+	zipper := gzip.NewWriter(&zipped)
+	zipper.Write([]byte(gollyGliderMacrocell))
+	zipper.Close()
+	unzipped, err := ReadMacrocell(&zipped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := populationCells(unzipped.Game.Population); !slices.Equal(got, want) {
+		t.Errorf("gzipped file got cells %v, want %v", got, want)
+	}
+}
+
+func TestMacrocellRoundTrip(t *testing.T) {
+	rule, err := ParseRule("B36/S23")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pattern := &Pattern{Game: golife.NewGame(), Rule: rule}
+	pattern.Game.Population = randomSoup(rand.New(rand.NewPCG(3, 4)), 40, -20)
+	pattern.Game.AddCell(golife.Cell{X: 1000, Y: -3000}) // far enough out to need a few more levels
+	pattern.Game.Generation = 123
+	pattern.Game.Name = "Soup"
+	pattern.Game.Author = "Someone"
+	pattern.Game.Comments = []string{"C a random soup"}
+
+	var buf bytes.Buffer
+	if err := WriteMacrocell(pattern, &buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadMacrocell(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := populationCells(read.Game.Population), populationCells(pattern.Game.Population); !slices.Equal(got, want) {
+		t.Errorf("got %d cells back, want %d", len(got), len(want))
+	}
+	if read.Rule != rule || read.Game.Generation != 123 || read.Game.Name != "Soup" || read.Game.Author != "Someone" {
+		t.Errorf("got rule %s, generation %d, name %q and author %q", read.Rule, read.Game.Generation, read.Game.Name, read.Game.Author)
+	}
+	if !slices.Equal(read.Game.Comments, pattern.Game.Comments) {
+		t.Errorf("got comments %q", read.Game.Comments)
+	}
+}
+
+func TestMacrocellErrors(t *testing.T) {
+	for _, contents := range []string{
+		"x = 3, y = 3\nbo$2bo$3o!\n",
+		"[M2]\n4 0 0 2 0\n",
+		"[M2]\n.*x$\n",
+		"[M2]\n#R B2/S/C3\n",
+	} {
+		if _, err := ReadMacrocell(strings.NewReader(contents)); err == nil {
+			t.Errorf("read %q without an error", contents)
+		}
+	}
+	brain := newTestPattern(golife.Cell{X: 0, Y: 0})
+	brain.Rule.States = 3
+	if err := WriteMacrocell(brain, &bytes.Buffer{}); err == nil {
+		t.Error("wrote a Generations pattern")
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
-	"runtime"
+	"os"
 	"time"
 
 	"github.com/pneumaticdeath/golife"
@@ -15,7 +15,6 @@ import (
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
-	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/widget"
@@ -90,6 +89,10 @@ func BuildExampleMenuItems(loader func(examples.Example) func()) []*fyne.MenuIte
 var mainWindow fyne.Window
 
 func main() {
+	if handled, status := runCommand(os.Args[1:]); handled {
+		os.Exit(status)
+	}
+
 	myApp := app.NewWithID("io.patenaude.gooeylife")
 	InitConfig(myApp)
 	mainWindow = myApp.NewWindow("Conway's Game of Life")
@@ -100,12 +103,7 @@ func main() {
 
 	var currentLC *LifeContainer
 
-	var modKey fyne.KeyModifier
-	if runtime.GOOS == "darwin" {
-		modKey = fyne.KeyModifierSuper
-	} else {
-		modKey = fyne.KeyModifierControl
-	}
+	keyBindings = NewKeyBindings(mainWindow)
 
 	updateSimMenu := func() { /* to be filled in later */ }
 
@@ -115,7 +113,7 @@ func main() {
 			updateSimMenu()
 		}
 	})
-	simEditCheckMI.Shortcut = &desktop.CustomShortcut{KeyName: fyne.KeyE, Modifier: modKey}
+	keyBindings.HandleMenuItem("edit_mode", simEditCheckMI)
 
 	simAutoZoomCheckMI := fyne.NewMenuItem("Auto Zoom", func() {
 		if currentLC != nil {
@@ -123,35 +121,79 @@ func main() {
 			updateSimMenu()
 		}
 	})
-	simAutoZoomCheckMI.Shortcut = &desktop.CustomShortcut{KeyName: fyne.KeyA, Modifier: modKey}
+	keyBindings.HandleMenuItem("auto_zoom", simAutoZoomCheckMI)
 
 	simZoomFitMI := fyne.NewMenuItem("Zoom To Fit", func() {
 		if currentLC != nil {
 			currentLC.Sim.ResizeToFit()
 		}
 	})
-	simZoomFitMI.Shortcut = &desktop.CustomShortcut{KeyName: fyne.KeyF, Modifier: modKey}
+	keyBindings.HandleMenuItem("zoom_fit", simZoomFitMI)
+
+	simCompareHighlightCheckMI := fyne.NewMenuItem("Highlight Differences", func() {
+		if currentLC != nil {
+			currentLC.SetHighlightDiffs(!currentLC.HighlightsDiffs())
+			updateSimMenu()
+		}
+	})
+	simCompareSwapMI := fyne.NewMenuItem("Swap Sides", func() {
+		if currentLC != nil {
+			currentLC.SwapCompared()
+		}
+	})
+	simCompareStopMI := fyne.NewMenuItem("Stop Comparing", func() {
+		if currentLC != nil {
+			currentLC.StopCompare()
+			updateSimMenu()
+		}
+	})
+
+	simKeepRunningCheckMI := fyne.NewMenuItem("Keep Running When Hidden", func() {
+		if currentLC != nil {
+			currentLC.Control.SetKeepRunning(!currentLC.Control.KeepRunning())
+			updateSimMenu()
+		}
+	})
+	keyBindings.HandleMenuItem("keep_running", simKeepRunningCheckMI)
+
+	// their actions are set once the tabs exist
+	simUndoMI := fyne.NewMenuItem("Undo", nil)
+	simRedoMI := fyne.NewMenuItem("Redo", nil)
 
 	updateSimMenu = func() {
 		if currentLC != nil {
+			simUndoMI.Disabled = !currentLC.Sim.Edits.CanUndo(currentLC.Sim.Game)
+			simRedoMI.Disabled = !currentLC.Sim.Edits.CanRedo(currentLC.Sim.Game)
 			simEditCheckMI.Checked = currentLC.Sim.IsEditable()
 			simAutoZoomCheckMI.Checked = currentLC.Sim.IsAutoZoom()
+			simKeepRunningCheckMI.Checked = currentLC.Control.KeepRunning()
+			simCompareHighlightCheckMI.Checked = currentLC.HighlightsDiffs()
+			simCompareHighlightCheckMI.Disabled = !currentLC.IsComparing()
+			simCompareSwapMI.Disabled = !currentLC.IsComparing()
+			simCompareStopMI.Disabled = !currentLC.IsComparing()
 		}
 	}
 
 	lc := NewLifeContainer(updateSimMenu)
 
 	tabs := NewLifeTabs(lc)
+	RestoreSession(tabs, func() *LifeContainer { return NewLifeContainer(updateSimMenu) })
 	currentLC = tabs.CurrentLifeContainer()
 	displayClock := StartDisplayUpdateClock(tabs)
 
 	tabs.DocTabs.OnSelected = func(ti *container.TabItem) {
 		currentLC = tabs.CurrentLifeContainer()
+		currentLC.Stamps.Reload()
+		tabs.UpdateRunStates()
 		updateSimMenu()
 	}
 
 	tabs.DocTabs.OnClosed = func(ti *container.TabItem) {
 		if len(tabs.DocTabs.Items) == 0 {
+			if lastLC, ok := ti.Content.(*LifeContainer); ok && !fyne.CurrentDevice().IsMobile() {
+				// the tabs are all gone by the time we quit, so save this one now
+				SaveSession([]*LifeContainer{lastLC}, 0)
+			}
 			if fyne.CurrentDevice().IsMobile() {
 				tabs.NewTab(NewLifeContainer(updateSimMenu))
 				updateSimMenu()
@@ -179,9 +221,12 @@ func main() {
 		newlc := NewLifeContainer(updateSimMenu)
 		tabs.NewTab(newlc)
 	})
-	newTabMenuItem.Shortcut = &desktop.CustomShortcut{KeyName: fyne.KeyN, Modifier: modKey}
+	keyBindings.HandleMenuItem("new_tab", newTabMenuItem)
 
 	closeTabMenuItem := fyne.NewMenuItem("Close current tab", func() {
+		if len(tabs.DocTabs.Items) == 1 && !fyne.CurrentDevice().IsMobile() {
+			SaveSession([]*LifeContainer{currentLC}, 0)
+		}
 		// clean up LC update thread
 		currentLC.StopClocks()
 		tabs.DocTabs.RemoveIndex(tabs.DocTabs.SelectedIndex())
@@ -202,13 +247,13 @@ func main() {
 			tabs.Refresh()
 		}
 	})
-	closeTabMenuItem.Shortcut = &desktop.CustomShortcut{KeyName: fyne.KeyW, Modifier: modKey}
+	keyBindings.HandleMenuItem("close_tab", closeTabMenuItem)
 
 	fileInfoMenuItem := fyne.NewMenuItem("Get info", func() {
 		title, content := currentLC.Sim.GetGameInfo()
 		dialog.ShowInformation(title, content, mainWindow)
 	})
-	fileInfoMenuItem.Shortcut = &desktop.CustomShortcut{KeyName: fyne.KeyI, Modifier: modKey}
+	keyBindings.HandleMenuItem("get_info", fileInfoMenuItem)
 
 	fileAboutMenuItem := fyne.NewMenuItem("About", func() {
 		aboutContent := container.New(layout.NewVBoxLayout(), GooeyLifeIconImage,
@@ -223,7 +268,11 @@ func main() {
 	fileSettingsMenuItem := fyne.NewMenuItem("Settings", func() {
 		Config.ShowPreferencesDialog(tabs, displayClock)
 	})
-	fileSettingsMenuItem.Shortcut = &desktop.CustomShortcut{KeyName: fyne.KeySemicolon, Modifier: modKey}
+	keyBindings.HandleMenuItem("settings", fileSettingsMenuItem)
+
+	fileShortcutsMenuItem := fyne.NewMenuItem("Keyboard Shortcuts...", func() {
+		ShowKeyBindingsDialog(keyBindings)
+	})
 
 	updateMainMenu := func() {
 		// to be built later
@@ -232,11 +281,17 @@ func main() {
 	fileLoadGameMenuItem := fyne.NewMenuItem("Load", nil)
 	savedGames := Config.SavedGames()
 	buildLoadSavedGamesMenu := func() {
-		if len(savedGames) > 0 {
-			mi := make([]*fyne.MenuItem, 0, len(savedGames))
-			for name, game := range savedGames {
+		names := savedGames.Names()
+		if len(names) > 0 {
+			mi := make([]*fyne.MenuItem, 0, len(names))
+			for _, name := range names {
 				mi = append(mi, fyne.NewMenuItem(name, func() {
-					tabs.SetCurrentGame(game.Copy())
+					pattern, err := savedGames.Load(name)
+					if err != nil {
+						dialog.ShowError(err, mainWindow)
+						return
+					}
+					tabs.SetCurrentPattern(pattern)
 				}))
 			}
 			fileLoadGameMenuItem.ChildMenu = fyne.NewMenu("Load", mi...)
@@ -244,44 +299,67 @@ func main() {
 			updateMainMenu()
 		} else {
 			fileLoadGameMenuItem.Disabled = true
+			updateMainMenu()
 		}
 	}
 	buildLoadSavedGamesMenu()
 
 	fileSaveGameMenuItem := fyne.NewMenuItem("Save..", func() {
-		currentGame := currentLC.Sim.Game.Copy()
-		name := currentGame.Name
+		currentPattern := currentLC.Sim.Pattern().Copy()
+		name := currentPattern.Game.Name
 		nameEntry := widget.NewEntry()
 		nameEntry.SetText(name)
 		formItems := []*widget.FormItem{widget.NewFormItem("Name:", nameEntry)}
 
 		dialog.ShowForm("Save game as..", "Save", "Cancel", formItems, func(saved bool) {
 			if saved {
-				savedGames[nameEntry.Text] = currentGame
-				Config.SetSavedGames(savedGames)
+				if err := savedGames.Save(nameEntry.Text, currentPattern); err != nil {
+					dialog.ShowError(err, mainWindow)
+				}
 				buildLoadSavedGamesMenu()
+				if openLibrary != nil {
+					openLibrary.Reload()
+				}
 			}
 		}, mainWindow)
 	})
 
+	fileLibraryMenuItem := fyne.NewMenuItem("Library...", func() {
+		ShowLibrary(func(pattern *Pattern) {
+			newlc := NewLifeContainer(updateSimMenu)
+			newlc.SetPattern(pattern)
+			tabs.NewTab(newlc)
+			tabs.Refresh()
+		}, buildLoadSavedGamesMenu)
+	})
+
+	fileOpenURLMenuItem := fyne.NewMenuItem("Open URL...", func() {
+		ShowOpenURLDialog(func(pattern *Pattern) {
+			newlc := NewLifeContainer(updateSimMenu)
+			newlc.SetPattern(pattern)
+			tabs.NewTab(newlc)
+			tabs.Refresh()
+		})
+	})
+
 	var fileMenu *fyne.Menu
 
 	if !fyne.CurrentDevice().IsMobile() {
-		lifeFileExtensionsFilter := &LongExtensionsFileFilter{Extensions: []string{".rle", ".rle.txt", ".life", ".life.txt", ".cells", ".cells.txt"}}
-		saveLifeExtensionsFilter := &LongExtensionsFileFilter{Extensions: []string{".rle", ".rle.txt"}}
+		lifeFileExtensionsFilter := &LongExtensionsFileFilter{Extensions: patternExtensions}
+		saveLifeExtensionsFilter := &LongExtensionsFileFilter{Extensions: []string{".rle", ".rle.txt", ".mc", ".mc.gz"}}
 
 		fileImportCallback := func(reader fyne.URIReadCloser, err error) {
 			if err != nil {
 				dialog.ShowError(err, mainWindow)
 			} else if reader != nil {
-				lifeReader := golife.FindReader(reader.URI().Name())
-				newGame, readErr := lifeReader(reader)
+				lifeReader := FindPatternReader(reader.URI().Name())
+				newPattern, readErr := lifeReader(reader)
 				defer reader.Close()
 				if readErr != nil {
 					dialog.ShowError(readErr, mainWindow)
 				} else {
-					newGame.Filename = reader.URI().Path()
-					tabs.SetCurrentGame(newGame)
+					newPattern.Game.Filename = reader.URI().Path()
+					tabs.SetCurrentPattern(newPattern)
 					tabs.Refresh()
 				}
 				// Now we save where we opend this file so that we can default to it next time.
@@ -299,7 +377,8 @@ func main() {
 				// writer.Close()  // hack for android save
 			}
 			if writer != nil {
-				write_err := currentLC.Sim.Game.WriteRLE(writer)
+				lifeWriter := FindPatternWriter(writer.URI().Name())
+				write_err := lifeWriter(currentLC.Sim.Pattern(), writer)
 				if write_err != nil {
 					dialog.ShowError(write_err, mainWindow)
 				}
@@ -326,13 +405,19 @@ func main() {
 			fileSave.Show()
 		})
 
+		fileExportImageMenuItem := fyne.NewMenuItem("Export Image...", func() {
+			currentLC.Control.StopSim()
+			ShowExportImageDialog(currentLC)
+		})
+
 		fileMenu = fyne.NewMenu("File", newTabMenuItem, closeTabMenuItem, fyne.NewMenuItemSeparator(),
-			fileLoadGameMenuItem, fileSaveGameMenuItem, fyne.NewMenuItemSeparator(),
-			fileImportMenuItem, fileExportMenuItem, fyne.NewMenuItemSeparator(),
-			fileInfoMenuItem, fileSettingsMenuItem, fileAboutMenuItem)
+			fileLoadGameMenuItem, fileSaveGameMenuItem, fileLibraryMenuItem, fyne.NewMenuItemSeparator(),
+			fileImportMenuItem, fileOpenURLMenuItem, fileExportMenuItem, fileExportImageMenuItem, fyne.NewMenuItemSeparator(),
+			fileInfoMenuItem, fileSettingsMenuItem, fileShortcutsMenuItem, fileAboutMenuItem)
 	} else {
 		fileMenu = fyne.NewMenu("File", newTabMenuItem, closeTabMenuItem, fyne.NewMenuItemSeparator(),
-			fileLoadGameMenuItem, fileSaveGameMenuItem, fyne.NewMenuItemSeparator(),
+			fileLoadGameMenuItem, fileSaveGameMenuItem, fileLibraryMenuItem, fyne.NewMenuItemSeparator(),
+			fileOpenURLMenuItem, fyne.NewMenuItemSeparator(),
 			fileInfoMenuItem, fileSettingsMenuItem, fileAboutMenuItem)
 	}
 
@@ -350,7 +435,7 @@ func main() {
 			games = append(games, examples.LoadExample(ex))
 		}
 		remaining := games
-		if currentLC.Sim.Game.Size() == 0 {
+		if currentLC.Sim.CellCount() == 0 {
 			tabs.SetCurrentGame(games[0])
 			remaining = games[1:]
 		}
@@ -378,7 +463,152 @@ func main() {
 			}, mainWindow)
 	})
 
-	simMenu := fyne.NewMenu("Sim", simAutoZoomCheckMI, simZoomFitMI, simEditCheckMI, simClearMI)
+	// Clipboard operations only make sense in edit mode, so they switch
+	// the current tab into it if it isn't already.
+	ensureEditMode := func() {
+		if !currentLC.Sim.IsEditable() {
+			currentLC.Sim.SetEditMode(true)
+		}
+	}
+
+	// the shortcuts still work when the menu items are disabled, so
+	// these do nothing when there's nothing to undo or redo
+	simUndoMI.Action = func() {
+		if currentLC.Undo() {
+			tabs.UpdateTitle()
+			updateSimMenu()
+		}
+	}
+	keyBindings.HandleMenuItem("undo", simUndoMI)
+
+	simRedoMI.Action = func() {
+		if currentLC.Redo() {
+			tabs.UpdateTitle()
+			updateSimMenu()
+		}
+	}
+	keyBindings.HandleMenuItem("redo", simRedoMI)
+
+	simCopyMI := fyne.NewMenuItem("Copy", func() {
+		err := currentLC.Sim.CopySelection(myApp.Clipboard())
+		if err != nil {
+			dialog.ShowError(err, mainWindow)
+		}
+	})
+	keyBindings.HandleMenuItem("copy", simCopyMI)
+
+	simCutMI := fyne.NewMenuItem("Cut", func() {
+		ensureEditMode()
+		err := currentLC.Sim.CutSelection(myApp.Clipboard())
+		if err != nil {
+			dialog.ShowError(err, mainWindow)
+		}
+	})
+	keyBindings.HandleMenuItem("cut", simCutMI)
+
+	simPasteMI := fyne.NewMenuItem("Paste", func() {
+		ensureEditMode()
+		err := currentLC.Sim.PasteFromClipboard(myApp.Clipboard())
+		if err != nil {
+			dialog.ShowError(err, mainWindow)
+		}
+	})
+	keyBindings.HandleMenuItem("paste", simPasteMI)
+
+	simDeleteMI := fyne.NewMenuItem("Delete Selection", func() {
+		ensureEditMode()
+		currentLC.Sim.DeleteSelection()
+	})
+
+	transformer := func(transform CellTransform) func() {
+		return func() {
+			currentLC.Control.StopSim()
+			currentLC.Sim.ApplyTransform(transform)
+		}
+	}
+
+	simRotateCWMI := fyne.NewMenuItem("Rotate Clockwise", transformer(RotateCW))
+	keyBindings.HandleMenuItem("rotate_cw", simRotateCWMI)
+	simRotateCCWMI := fyne.NewMenuItem("Rotate Counterclockwise", transformer(RotateCCW))
+	keyBindings.HandleMenuItem("rotate_ccw", simRotateCCWMI)
+	simFlipHorizontalMI := fyne.NewMenuItem("Flip Horizontal", transformer(FlipHorizontal))
+	keyBindings.HandleMenuItem("flip_horizontal", simFlipHorizontalMI)
+	simFlipVerticalMI := fyne.NewMenuItem("Flip Vertical", transformer(FlipVertical))
+	keyBindings.HandleMenuItem("flip_vertical", simFlipVerticalMI)
+
+	simNudgeUpMI := fyne.NewMenuItem("Up", transformer(Translate(0, -1)))
+	keyBindings.HandleMenuItem("nudge_up", simNudgeUpMI)
+	simNudgeDownMI := fyne.NewMenuItem("Down", transformer(Translate(0, 1)))
+	keyBindings.HandleMenuItem("nudge_down", simNudgeDownMI)
+	simNudgeLeftMI := fyne.NewMenuItem("Left", transformer(Translate(-1, 0)))
+	keyBindings.HandleMenuItem("nudge_left", simNudgeLeftMI)
+	simNudgeRightMI := fyne.NewMenuItem("Right", transformer(Translate(1, 0)))
+	keyBindings.HandleMenuItem("nudge_right", simNudgeRightMI)
+	simNudgeMI := fyne.NewMenuItem("Nudge", nil)
+	simNudgeMI.ChildMenu = fyne.NewMenu("Nudge", simNudgeUpMI, simNudgeDownMI, simNudgeLeftMI, simNudgeRightMI)
+
+	simRuleMI := fyne.NewMenuItem("Rule...", func() {
+		ShowRuleDialog(currentLC)
+	})
+
+	simRunAllMI := fyne.NewMenuItem("Run All Tabs", func() {
+		tabs.RunAll()
+		updateSimMenu()
+	})
+	keyBindings.HandleMenuItem("run_all", simRunAllMI)
+	simPauseAllMI := fyne.NewMenuItem("Pause All Tabs", tabs.PauseAll)
+	keyBindings.HandleMenuItem("pause_all", simPauseAllMI)
+
+	simShowGridCheckMI := fyne.NewMenuItem("Show Grid", nil)
+	simShowGridCheckMI.Action = func() {
+		Config.SetShowGrid(!Config.ShowGrid())
+		simShowGridCheckMI.Checked = Config.ShowGrid()
+		tabs.RedrawAll()
+	}
+	simShowGridCheckMI.Checked = Config.ShowGrid()
+	keyBindings.HandleMenuItem("show_grid", simShowGridCheckMI)
+	simShowRulersCheckMI := fyne.NewMenuItem("Show Rulers", nil)
+	simShowRulersCheckMI.Action = func() {
+		Config.SetShowRulers(!Config.ShowRulers())
+		simShowRulersCheckMI.Checked = Config.ShowRulers()
+		tabs.RedrawAll()
+	}
+	simShowRulersCheckMI.Checked = Config.ShowRulers()
+	keyBindings.HandleMenuItem("show_rulers", simShowRulersCheckMI)
+
+	simRandomFillMI := fyne.NewMenuItem("Random Fill...", func() {
+		ShowRandomFillDialog(currentLC)
+	})
+	keyBindings.HandleMenuItem("random_fill", simRandomFillMI)
+
+	simGoToMI := fyne.NewMenuItem("Go to Generation...", func() {
+		ShowGoToGenerationDialog(currentLC)
+	})
+	keyBindings.HandleMenuItem("goto_generation", simGoToMI)
+	simAddBookmarkMI := fyne.NewMenuItem("Add Bookmark...", func() {
+		ShowAddBookmarkDialog(currentLC)
+	})
+	keyBindings.HandleMenuItem("add_bookmark", simAddBookmarkMI)
+	simBookmarksMI := fyne.NewMenuItem("Bookmarks...", func() {
+		ShowBookmarksDialog(currentLC)
+	})
+
+	simCompareCopyMI := fyne.NewMenuItem("With a Copy", func() {
+		currentLC.StartCompare(currentLC.Sim.Pattern().Copy())
+		updateSimMenu()
+	})
+	simCompareTabMI := fyne.NewMenuItem("With Another Tab...", func() {
+		ShowCompareTabDialog(tabs, currentLC, updateSimMenu)
+	})
+	simCompareMI := fyne.NewMenuItem("Compare", nil)
+	simCompareMI.ChildMenu = fyne.NewMenu("Compare", simCompareCopyMI, simCompareTabMI, fyne.NewMenuItemSeparator(),
+		simCompareHighlightCheckMI, simCompareSwapMI, simCompareStopMI)
+
+	simMenu := fyne.NewMenu("Sim", simAutoZoomCheckMI, simZoomFitMI, simShowGridCheckMI, simShowRulersCheckMI, simEditCheckMI, simClearMI, simRandomFillMI, simRuleMI, simCompareMI, fyne.NewMenuItemSeparator(),
+		simGoToMI, simAddBookmarkMI, simBookmarksMI, fyne.NewMenuItemSeparator(),
+		simUndoMI, simRedoMI, simCopyMI, simCutMI, simPasteMI, simDeleteMI, fyne.NewMenuItemSeparator(),
+		simRotateCWMI, simRotateCCWMI, simFlipHorizontalMI, simFlipVerticalMI, simNudgeMI, fyne.NewMenuItemSeparator(),
+		simKeepRunningCheckMI, simRunAllMI, simPauseAllMI)
 
 	mainMenu := fyne.NewMainMenu(fileMenu, simMenu, examplesMenu, helpMenu)
 
@@ -390,27 +620,41 @@ func main() {
 
 	mainWindow.SetContent(tabs)
 
-	toggleRun := func(shortcut fyne.Shortcut) {
+	keyBindings.Handle("run_stop", func() {
 		if currentLC.Control.IsRunning() {
 			currentLC.Control.StopSim()
 		} else {
 			currentLC.Control.StartSim()
 		}
-	}
+	})
+	keyBindings.Handle("step_forward", func() { currentLC.Control.StepOrStop() })
+	keyBindings.Handle("step_backward", func() { currentLC.Control.StepBackward() })
+	keyBindings.Handle("faster", func() { currentLC.Control.Faster() })
+	keyBindings.Handle("slower", func() { currentLC.Control.Slower() })
+	keyBindings.Handle("zoom_in", func() { currentLC.Control.ZoomIn() })
+	keyBindings.Handle("zoom_out", func() { currentLC.Control.ZoomOut() })
+	keyBindings.Handle("pan_up", func() { currentLC.Sim.ShiftUp() })
+	keyBindings.Handle("pan_down", func() { currentLC.Sim.ShiftDown() })
+	keyBindings.Handle("pan_left", func() { currentLC.Sim.ShiftLeft() })
+	keyBindings.Handle("pan_right", func() { currentLC.Sim.ShiftRight() })
+	keyBindings.SetMainMenu(mainMenu)
+	keyBindings.Apply()
 
-	mainWindow.Canvas().AddShortcut(&desktop.CustomShortcut{KeyName: fyne.KeyR, Modifier: modKey}, toggleRun)
 	keyPressHandler := func(keyEvent *fyne.KeyEvent) {
+		if keyBindings.TypedKey(keyEvent) {
+			return
+		}
 		switch keyEvent.Name {
-		case fyne.KeyUp:
-			currentLC.Sim.ShiftUp()
-		case fyne.KeyDown:
-			currentLC.Sim.ShiftDown()
-		case fyne.KeyLeft:
-			currentLC.Sim.ShiftLeft()
-		case fyne.KeyRight:
-			currentLC.Sim.ShiftRight()
-		case fyne.KeyR:
-			toggleRun(nil)
+		case fyne.KeyDelete, fyne.KeyBackspace:
+			if currentLC.Sim.IsEditable() {
+				currentLC.Sim.DeleteSelection()
+			}
+		case fyne.KeyEscape:
+			if currentLC.Sim.IsPasting() {
+				currentLC.Sim.CancelPaste()
+			} else {
+				currentLC.Sim.ClearSelection()
+			}
 		default:
 			// fmt.Println("Got unexpected key", keyEvent.Name)
 		}
@@ -419,38 +663,48 @@ func main() {
 
 	mainWindow.SetOnDropped(func(pos fyne.Position, files []fyne.URI) {
 		if len(files) >= 1 {
-			games := make([]*golife.Game, 0, len(files))
+			patterns := make([]*Pattern, 0, len(files))
 			for index := range files {
-				gameParser := golife.FindReader(files[index].Name())
+				gameParser := FindPatternReader(files[index].Name())
 				gameReader, err := storage.Reader(files[index])
 				if err != nil {
 					dialog.ShowError(err, mainWindow)
 					continue
 				}
-				newGame, err := gameParser(gameReader)
+				newPattern, err := gameParser(gameReader)
+				gameReader.Close()
 				if err != nil {
 					dialog.ShowError(err, mainWindow)
-				} else if newGame != nil {
-					newGame.Filename = files[index].Path()
-					games = append(games, newGame)
+				} else if newPattern != nil {
+					newPattern.Game.Filename = files[index].Path()
+					patterns = append(patterns, newPattern)
 				}
 			}
+			if len(patterns) == 0 {
+				return
+			}
 
-			remaining := games
-			if currentLC.Sim.Game.Size() == 0 {
+			remaining := 0
+			if currentLC.Sim.CellCount() == 0 {
 				currentLC.Control.StopSim()
-				tabs.SetCurrentGame(games[0])
-				remaining = games[1:]
+				tabs.SetCurrentPattern(patterns[0])
+				remaining = 1
 			}
-			for index := range remaining {
+			for index := remaining; index < len(patterns); index++ {
 				lc = NewLifeContainer(updateSimMenu)
-				lc.SetGame(remaining[index])
+				lc.SetPattern(patterns[index])
 				tabs.NewTab(lc)
 			}
 			tabs.Refresh()
 		}
 	})
 
+	myApp.Lifecycle().SetOnStopped(func() {
+		if len(tabs.DocTabs.Items) > 0 {
+			SaveSession(tabs.GetLifeContainters(), tabs.DocTabs.SelectedIndex())
+		}
+	})
+
 	if Config.ShowGuidedTour() {
 		ShowGuidedTour()
 	}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pneumaticdeath/golife"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Loading patterns from the web.  Links to pattern files are fetched and
+// parsed by their extension, or by their Content-Type (or contents) when
+// the URL doesn't have one.  A LifeWiki page is swapped for the pattern
+// file it describes, and catagolue object links carry the whole pattern
+// in their apgcode, so those don't need fetching at all.
+
+const (
+	maxPatternDownload  = 64 << 20 // bytes
+	patternFetchTimeout = 30 * time.Second
+)
+
+// patternExtensions are the file extensions we know how to read
+var patternExtensions = []string{".rle", ".rle.txt", ".life", ".life.txt", ".cells", ".cells.txt", ".mc", ".mc.gz"}
+
+func hasPatternExtension(name string) bool {
+	for _, ext := range patternExtensions {
+		if strings.HasSuffix(strings.ToLower(name), ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// FetchPattern loads the pattern at the URL
+func FetchPattern(client *http.Client, rawURL string) (*Pattern, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("Can only load patterns over http or https, not %q", u.Scheme)
+	}
+
+	if apgcode, rule, found := catagolueObject(u); found {
+		return DecodeApgcode(apgcode, rule)
+	}
+	u = lifeWikiPatternURL(u)
+
+	response, err := client.Get(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Unable to fetch %s: %s", u, response.Status)
+	}
+	contents, err := io.ReadAll(io.LimitReader(response.Body, maxPatternDownload+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(contents) > maxPatternDownload {
+		return nil, fmt.Errorf("Pattern is more than %d MB", maxPatternDownload>>20)
+	}
+
+	name := path.Base(u.Path)
+	if !hasPatternExtension(name) {
+		name = "pattern" + patternTypeExtension(response.Header.Get("Content-Type"), contents)
+	}
+	return FindPatternReader(strings.ToLower(name))(bytes.NewReader(contents))
+}
+
+// patternTypeExtension guesses the kind of pattern file from its
+// Content-Type, and failing that from what's in it.
+func patternTypeExtension(contentType string, contents []byte) string {
+	contentType = strings.ToLower(contentType)
+	switch {
+	case strings.Contains(contentType, "macrocell"):
+		return ".mc"
+	case strings.Contains(contentType, "gzip"):
+		return ".mc.gz"
+	case strings.Contains(contentType, "rle"):
+		return ".rle"
+	case strings.Contains(contentType, "cells"):
+		return ".cells"
+	case strings.Contains(contentType, "life"):
+		return ".life"
+	}
+
+	switch {
+	case bytes.HasPrefix(contents, []byte{0x1f, 0x8b}):
+		return ".mc.gz"
+	case bytes.HasPrefix(contents, []byte("[M2]")):
+		return ".mc"
+	case bytes.HasPrefix(contents, []byte("#Life")):
+		return ".life"
+	case bytes.HasPrefix(contents, []byte("!")):
+		return ".cells"
+	}
+	return ".rle"
+}
+
+// lifeWikiPatternURL turns a link to a LifeWiki page into a link to its
+// pattern file, e.g. conwaylife.com/wiki/Gosper_glider_gun becomes
+// conwaylife.com/patterns/gosperglidergun.rle.  Other links are left alone.
+func lifeWikiPatternURL(u *url.URL) *url.URL {
+	if !strings.HasSuffix(u.Hostname(), "conwaylife.com") || !strings.HasPrefix(u.Path, "/wiki/") {
+		return u
+	}
+	page := strings.TrimPrefix(u.Path, "/wiki/")
+	if page == "" || strings.Contains(page, ":") {
+		return u
+	}
+	name := strings.ToLower(strings.NewReplacer("_", "", " ", "", "-", "").Replace(page))
+	patternURL := *u
+	patternURL.Path = "/patterns/" + name + ".rle"
+	patternURL.RawQuery, patternURL.Fragment = "", ""
+	return &patternURL
+}
+
+// catagolueObject picks the apgcode and rule out of a catagolue link like
+// catagolue.hatsya.com/object/xq4_153/b3s23
+func catagolueObject(u *url.URL) (string, string, bool) {
+	if !strings.Contains(u.Hostname(), "catagolue") {
+		return "", "", false
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "object" {
+		return "", "", false
+	}
+	rule := "b3s23"
+	if len(parts) >= 3 {
+		rule = parts[2]
+	}
+	return parts[1], rule, true
+}
+
+// DecodeApgcode builds the pattern described by an apgcode for a still
+// life, oscillator or spaceship, like xs4_33 or xq4_153.  Those are in
+// extended Wechsler format, where each character is a column five cells
+// tall with the top cell in the lowest bit.
+func DecodeApgcode(apgcode, ruleStr string) (*Pattern, error) {
+	rule, err := ParseRule(ruleStr)
+	if err != nil {
+		return nil, err
+	}
+	prefix, code, found := strings.Cut(apgcode, "_")
+	if !found || len(prefix) < 2 || !strings.ContainsRune("spq", rune(prefix[1])) || prefix[0] != 'x' {
+		return nil, fmt.Errorf("Can only decode still lifes, oscillators and spaceships, not %s", apgcode)
+	}
+
+	game := golife.NewGame()
+	game.Name = apgcode
+	var x, y golife.Coord
+	for index := 0; index < len(code); index++ {
+		c := code[index]
+		switch {
+		case c == 'w':
+			x += 2
+		case c == 'x':
+			x += 3
+		case c == 'y':
+			index++
+			if index >= len(code) {
+				return nil, errors.New("apgcode ends in the middle of a run of blanks")
+			}
+			blanks := strings.IndexByte("0123456789abcdefghijklmnopqrstuvwxyz", code[index])
+			if blanks < 0 {
+				return nil, fmt.Errorf("Bad character %c in apgcode", code[index])
+			}
+			x += golife.Coord(blanks + 4)
+		case c == 'z':
+			x, y = 0, y+5
+		default:
+			column := strings.IndexByte("0123456789abcdefghijklmnopqrstuv", c)
+			if column < 0 {
+				return nil, fmt.Errorf("Bad character %c in apgcode", c)
+			}
+			for bit := 0; bit < 5; bit++ {
+				if column&(1<<bit) != 0 {
+					game.AddCell(golife.Cell{X: x, Y: y + golife.Coord(bit)})
+				}
+			}
+			x++
+		}
+	}
+	return &Pattern{Game: game, Rule: rule}, nil
+}
+
+// ShowOpenURLDialog asks for a URL and loads the pattern from it in the
+// background, handing it to open when it arrives.
+func ShowOpenURLDialog(open func(*Pattern)) {
+	urlEntry := widget.NewEntry()
+	urlEntry.SetPlaceHolder("https://conwaylife.com/patterns/glider.rle")
+	formItems := []*widget.FormItem{widget.NewFormItem("URL:", urlEntry)}
+	dialog.ShowForm("Open URL", "Open", "Cancel", formItems, func(confirmed bool) {
+		rawURL := strings.TrimSpace(urlEntry.Text)
+		if !confirmed || rawURL == "" {
+			return
+		}
+		progress := dialog.NewCustomWithoutButtons("Loading", widget.NewProgressBarInfinite(), mainWindow)
+		progress.Show()
+		go func() {
+			pattern, err := FetchPattern(&http.Client{Timeout: patternFetchTimeout}, rawURL)
+			fyne.Do(func() {
+				progress.Hide()
+				if err != nil {
+					dialog.ShowError(err, mainWindow)
+					return
+				}
+				pattern.Game.Filename = rawURL
+				open(pattern)
+			})
+		}()
+	}, mainWindow)
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pneumaticdeath/golife"
+)
+
+func populationCells(population golife.Population) []golife.Cell {
+	cells := make([]golife.Cell, 0, len(population))
+	for cell := range population {
+		cells = append(cells, cell)
+	}
+	return sortedCells(cells)
+}
+
+// the glider as it's written in all the test files
+var testGlider = sortedCells([]golife.Cell{{X: 1, Y: 0}, {X: 2, Y: 1}, {X: 0, Y: 2}, {X: 1, Y: 2}, {X: 2, Y: 2}})
+
+const (
+	gliderRLE       = "x = 3, y = 3, rule = B3/S23\nbo$2bo$3o!\n"
+	gliderPlaintext = ".O\n..O\nOOO\n"
+)
+
+type zeroReader struct{}
+
+func (zeroReader) Read(buf []byte) (int, error) {
+	clear(buf)
+	return len(buf), nil
+}
+
+func newPatternServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	serve := func(route, contentType, body string) {
+		mux.HandleFunc(route, func(w http.ResponseWriter, _ *http.Request) {
+			if contentType != "" {
+				w.Header().Set("Content-Type", contentType)
+			}
+			io.WriteString(w, body)
+		})
+	}
+	serve("/glider.rle", "text/plain", gliderRLE)
+	serve("/glider.cells", "application/octet-stream", gliderPlaintext)
+	serve("/typed", "application/x-cells", gliderPlaintext)
+	serve("/typed-rle", "application/x-rle", gliderRLE)
+	serve("/sniffed", "text/plain", "!Name: Glider\n"+gliderPlaintext)
+	serve("/sniffed-rle", "text/plain", gliderRLE)
+	mux.HandleFunc("/huge.rle", func(w http.ResponseWriter, _ *http.Request) {
+		io.CopyN(w, zeroReader{}, maxPatternDownload+1)
+	})
+	mux.HandleFunc("/missing.rle", http.NotFound)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchPattern(t *testing.T) {
+	server := newPatternServer(t)
+	tests := []struct {
+		name  string
+		path  string
+		error string // part of the error expected, or empty if it should load
+	}{
+		{"rle by extension", "/glider.rle", ""},
+		{"plaintext by extension, ignoring the content type", "/glider.cells", ""},
+		{"plaintext by content type", "/typed", ""},
+		{"rle by content type", "/typed-rle", ""},
+		{"plaintext by sniffing", "/sniffed", ""},
+		{"rle by default", "/sniffed-rle", ""},
+		{"not found", "/missing.rle", "404"},
+		{"too big", "/huge.rle", "more than"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pattern, err := FetchPattern(server.Client(), server.URL+test.path)
+			if test.error != "" {
+				if err == nil || !strings.Contains(err.Error(), test.error) {
+					t.Fatalf("got error %v, want one containing %q", err, test.error)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			// golife's plaintext reader counts the comment lines as rows
+			if got := populationCells(normalizePopulation(pattern.Game.Population)); !slices.Equal(got, testGlider) {
+				t.Errorf("got cells %v, want %v", got, testGlider)
+			}
+		})
+	}
+}
+
+func TestFetchPatternSchemes(t *testing.T) {
+	for _, rawURL := range []string{"file:///etc/passwd", "ftp://example.com/glider.rle", "glider.rle", "://bad"} {
+		if _, err := FetchPattern(http.DefaultClient, rawURL); err == nil {
+			t.Errorf("fetching %q didn't fail", rawURL)
+		}
+	}
+}
+
+func TestPatternTypeExtension(t *testing.T) {
+	tests := []struct {
+		contentType string
+		contents    string
+		want        string
+	}{
+		{"application/x-macrocell", "", ".mc"},
+		{"application/gzip", "", ".mc.gz"},
+		{"application/x-life", "", ".life"},
+		{"text/plain", "\x1f\x8b\x08", ".mc.gz"},
+		{"text/plain", "[M2] (golly 4.0)", ".mc"},
+		{"", "#Life 1.06\n0 0\n", ".life"},
+		{"", "!Name: Glider", ".cells"},
+		{"", "#N Glider\nx = 3", ".rle"},
+	}
+	for _, test := range tests {
+		if got := patternTypeExtension(test.contentType, []byte(test.contents)); got != test.want {
+			t.Errorf("patternTypeExtension(%q, %q) = %q, want %q", test.contentType, test.contents, got, test.want)
+		}
+	}
+}
+
+func TestDecodeApgcode(t *testing.T) {
+	tests := []struct {
+		apgcode string
+		rule    string
+		want    []golife.Cell
+		fails   bool
+	}{
+		{"xs4_33", "b3s23", []golife.Cell{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 0}, {X: 1, Y: 1}}, false},
+		{"xq4_153", "b3s23", []golife.Cell{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 2}, {X: 2, Y: 0}, {X: 2, Y: 1}}, false},
+		{"xp2_7", "b3s23", []golife.Cell{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 0, Y: 2}}, false},
+		{"xs2_1w1", "b3s23", []golife.Cell{{X: 0, Y: 0}, {X: 3, Y: 0}}, false},
+		{"xs2_1x1", "b3s23", []golife.Cell{{X: 0, Y: 0}, {X: 4, Y: 0}}, false},
+		{"xs2_1y01", "b3s23", []golife.Cell{{X: 0, Y: 0}, {X: 5, Y: 0}}, false},
+		{"xs2_1ya1", "b3s23", []golife.Cell{{X: 0, Y: 0}, {X: 15, Y: 0}}, false},
+		{"xs2_1z1", "b3s23", []golife.Cell{{X: 0, Y: 0}, {X: 0, Y: 5}}, false},
+		{"xs4_33", "b36s23", []golife.Cell{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 0}, {X: 1, Y: 1}}, false},
+		{"xs4_3y", "b3s23", nil, true},
+		{"xs4_3!", "b3s23", nil, true},
+		{"yl144_1_16_afb5f3db909e60548f086e22ee3353ac", "b3s23", nil, true},
+		{"xs4", "b3s23", nil, true},
+		{"xs4_33", "nonsense", nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.apgcode+"/"+test.rule, func(t *testing.T) {
+			pattern, err := DecodeApgcode(test.apgcode, test.rule)
+			if test.fails {
+				if err == nil {
+					t.Fatal("didn't fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := populationCells(pattern.Game.Population); !slices.Equal(got, sortedCells(test.want)) {
+				t.Errorf("got cells %v, want %v", got, sortedCells(test.want))
+			}
+		})
+	}
+}
+
+func TestFetchCatagolueObject(t *testing.T) {
+	// catagolue links are decoded without being fetched, so this client
+	// would fail if it were used
+	client := &http.Client{Transport: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		t.Fatal("catagolue object was fetched")
+		return nil, nil
+	})}
+	pattern, err := FetchPattern(client, "https://catagolue.hatsya.com/object/xq4_153/b3s23")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pattern.Game.Size() != 5 {
+		t.Errorf("got %d cells, want 5", pattern.Game.Size())
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
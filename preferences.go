@@ -4,9 +4,6 @@ import (
 	"fmt"
 	"image/color"
 	"strconv"
-	"strings"
-
-	"github.com/pneumaticdeath/golife"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/data/validation"
@@ -25,10 +22,24 @@ const (
 	runningCellColorKey   = "io.patenaude.gooeylife.running_color"
 	editCellColorKey      = "io.patenaude.gooeyLife.edit_color"
 	backgroundColorKey    = "io.patenaude.gooeyLife.background_color"
+	diffCellColorKey      = "io.patenaude.gooeylife.diff_color"
 	showGuidedTourKey     = "io.patenaude.gooeylife.guided_tour"
 	scrollAsZoomKey       = "io.patenaude.gooeylife.scroll_as_zoom"
-	savedGamesKey         = "io.patenaude.gooeylife.saved_games"
+	savedGamesKey         = "io.patenaude.gooeylife.saved_games" // only read to migrate to the SavedGameStore
+	pauseWhenSettledKey   = "io.patenaude.gooeylife.pause_when_settled"
+	restoreSessionKey     = "io.patenaude.gooeylife.restore_session"
+	sessionTabsKey        = "io.patenaude.gooeylife.session_tabs"
+	keyBindingKeyPrefix   = "io.patenaude.gooeylife.keys." // followed by the action
+	brushSizeKey          = "io.patenaude.gooeylife.brush_size"
+	brushShapeKey         = "io.patenaude.gooeylife.brush_shape"
+	userStampsKey         = "io.patenaude.gooeylife.stamps"
+	showGridKey           = "io.patenaude.gooeylife.show_grid"
+	showRulersKey         = "io.patenaude.gooeylife.show_rulers"
+	gridSpacingKey        = "io.patenaude.gooeylife.grid_spacing"
+	gridColorKey          = "io.patenaude.gooeylife.grid_color"
 	defaultHistorySize    = 10
+	defaultGridSpacing    = 10
+	maxGridSpacing        = 1000
 )
 
 var (
@@ -36,6 +47,8 @@ var (
 	defaultRunningColor color.Color = color.NRGBA{R: 0, G: 255, B: 0, A: 255}
 	defaultEditColor    color.Color = color.NRGBA{R: 255, G: 255, B: 0, A: 255}
 	defaultBGColor      color.Color = color.NRGBA{R: 0, G: 0, B: 0, A: 255}
+	defaultDiffColor    color.Color = color.NRGBA{R: 255, G: 0, B: 0, A: 255}
+	defaultGridColor    color.Color = color.NRGBA{R: 96, G: 96, B: 96, A: 255}
 )
 
 type ConfigT struct {
@@ -80,53 +93,69 @@ func (c ConfigT) SetScrollAsZoom(saz bool) {
 	c.app.Preferences().SetBool(scrollAsZoomKey, saz)
 }
 
-func (c ConfigT) DisplayRefreshRate() int {
-	return c.app.Preferences().IntWithFallback(displayRefreshRateKey, 60)
+func (c ConfigT) PauseWhenSettled() bool {
+	return c.app.Preferences().BoolWithFallback(pauseWhenSettledKey, false)
 }
 
-func (c ConfigT) SetDisplayRefreshRate(rate int) {
-	c.app.Preferences().SetInt(displayRefreshRateKey, rate)
+func (c ConfigT) SetPauseWhenSettled(pause bool) {
+	c.app.Preferences().SetBool(pauseWhenSettledKey, pause)
 }
 
-func (c ConfigT) SavedGames() map[string]*golife.Game {
-	gameStrs := c.app.Preferences().StringList(savedGamesKey)
-	games := make(map[string]*golife.Game)
-	blankCounter := 1
-	for _, rleData := range gameStrs {
-		reader := strings.NewReader(rleData)
-		game, err := golife.ReadRLE(reader)
-		if err != nil {
-			fyne.LogError("Unable to decode saved game", err)
-			continue
-		}
-		name := game.Name
-		for name == "" {
-			name = fmt.Sprintf("Blank game %d", blankCounter)
-			_, present := games[name]
-			if present {
-				name = ""
-			}
-			blankCounter += 1
-		}
-		games[name] = game
-	}
+func (c ConfigT) BrushSize() int {
+	return c.app.Preferences().IntWithFallback(brushSizeKey, 1)
+}
 
-	return games
+func (c ConfigT) SetBrushSize(size int) {
+	c.app.Preferences().SetInt(brushSizeKey, size)
 }
 
-func (c ConfigT) SetSavedGames(games map[string]*golife.Game) {
-	gameStrs := make([]string, 0, len(games))
-	for name, game := range games {
-		game.Name = name
-		var writer strings.Builder
-		err := game.WriteRLE(&writer)
-		if err != nil {
-			fyne.LogError(fmt.Sprintf("Unable to encode game %s", name), err)
-			continue
-		}
-		gameStrs = append(gameStrs, writer.String())
-	}
-	c.app.Preferences().SetStringList(savedGamesKey, gameStrs)
+func (c ConfigT) BrushShape() string {
+	return c.app.Preferences().StringWithFallback(brushShapeKey, brushSquare)
+}
+
+func (c ConfigT) SetBrushShape(shape string) {
+	c.app.Preferences().SetString(brushShapeKey, shape)
+}
+
+func (c ConfigT) ShowGrid() bool {
+	return c.app.Preferences().BoolWithFallback(showGridKey, false)
+}
+
+func (c ConfigT) SetShowGrid(show bool) {
+	c.app.Preferences().SetBool(showGridKey, show)
+}
+
+func (c ConfigT) ShowRulers() bool {
+	return c.app.Preferences().BoolWithFallback(showRulersKey, false)
+}
+
+func (c ConfigT) SetShowRulers(show bool) {
+	c.app.Preferences().SetBool(showRulersKey, show)
+}
+
+// GridSpacing is how many cells apart the major grid lines are
+func (c ConfigT) GridSpacing() int {
+	return max(1, min(maxGridSpacing, c.app.Preferences().IntWithFallback(gridSpacingKey, defaultGridSpacing)))
+}
+
+func (c ConfigT) SetGridSpacing(spacing int) {
+	c.app.Preferences().SetInt(gridSpacingKey, spacing)
+}
+
+func (c ConfigT) RestoreSession() bool {
+	return c.app.Preferences().BoolWithFallback(restoreSessionKey, true)
+}
+
+func (c ConfigT) SetRestoreSession(restore bool) {
+	c.app.Preferences().SetBool(restoreSessionKey, restore)
+}
+
+func (c ConfigT) DisplayRefreshRate() int {
+	return c.app.Preferences().IntWithFallback(displayRefreshRateKey, 60)
+}
+
+func (c ConfigT) SetDisplayRefreshRate(rate int) {
+	c.app.Preferences().SetInt(displayRefreshRateKey, rate)
 }
 
 func (c ConfigT) PausedCellColor() color.Color {
@@ -161,6 +190,25 @@ func (c ConfigT) SetBackgroundColor(clr color.Color) {
 	c.setColor(backgroundColorKey, clr)
 }
 
+// DiffCellColor is for live cells that differ from the comparison
+func (c ConfigT) DiffCellColor() color.Color {
+	return c.fetchColor(diffCellColorKey, defaultDiffColor)
+}
+
+func (c ConfigT) SetDiffCellColor(clr color.Color) {
+	c.setColor(diffCellColorKey, clr)
+}
+
+// GridColor is for the major grid lines and the rulers, the minor grid
+// lines are faded into the background
+func (c ConfigT) GridColor() color.Color {
+	return c.fetchColor(gridColorKey, defaultGridColor)
+}
+
+func (c ConfigT) SetGridColor(clr color.Color) {
+	c.setColor(gridColorKey, clr)
+}
+
 func (c ConfigT) fetchColor(key string, def color.Color) color.Color {
 	attr := c.app.Preferences().IntListWithFallback(key, make([]int, 0))
 	if len(attr) != 4 {
@@ -184,6 +232,10 @@ func (c ConfigT) ShowPreferencesDialog(tabs *LifeTabs, clk *DisplayUpdateClock)
 	historySizeEntry.SetText(fmt.Sprintf("%d", c.HistorySize()))
 	autoZoomDefaultCheck := widget.NewCheck("Auto Zoom by default", func(_ bool) {})
 	autoZoomDefaultCheck.SetChecked(c.AutoZoomDefault())
+	pauseWhenSettledCheck := widget.NewCheck("Pause when the pattern settles", func(_ bool) {})
+	pauseWhenSettledCheck.SetChecked(c.PauseWhenSettled())
+	restoreSessionCheck := widget.NewCheck("Reopen tabs from last time", func(_ bool) {})
+	restoreSessionCheck.SetChecked(c.RestoreSession())
 	displayRefreshRateSelector := widget.NewSelect([]string{"30Hz", "60Hz"}, func(_ string) {})
 	if clk.DisplayUpdateHz == 60 {
 		displayRefreshRateSelector.SetSelectedIndex(1)
@@ -223,6 +275,30 @@ func (c ConfigT) ShowPreferencesDialog(tabs *LifeTabs, clk *DisplayUpdateClock)
 		picker.SetColor(c.EditCellColor())
 		picker.Show()
 	})
+	diffColorPickerButton := widget.NewButtonWithIcon("Differing cells", theme.ColorPaletteIcon(), func() {
+		picker := dialog.NewColorPicker("Differing Cell Color", "", func(clr color.Color) {
+			c.SetDiffCellColor(clr)
+			mainWindow.Canvas().Content().Refresh()
+		}, mainWindow)
+		picker.Advanced = true
+		picker.SetColor(c.DiffCellColor())
+		picker.Show()
+	})
+	gridColorPickerButton := widget.NewButtonWithIcon("Grid lines", theme.ColorPaletteIcon(), func() {
+		picker := dialog.NewColorPicker("Grid Color", "", func(clr color.Color) {
+			c.SetGridColor(clr)
+			mainWindow.Canvas().Content().Refresh()
+		}, mainWindow)
+		picker.Advanced = true
+		picker.SetColor(c.GridColor())
+		picker.Show()
+	})
+	gridSpacingEntry := widget.NewEntry()
+	gridSpacingEntry.Validator = validation.NewRegexp(`^[1-9]\d{0,2}$`, "a whole number from 1 to 999")
+	gridSpacingEntry.SetText(strconv.Itoa(c.GridSpacing()))
+	shortcutsButton := widget.NewButton("Keyboard shortcuts", func() {
+		ShowKeyBindingsDialog(keyBindings)
+	})
 	backgroundColorPickerButton := widget.NewButtonWithIcon("Background", theme.ColorPaletteIcon(), func() {
 		picker := dialog.NewColorPicker("Background Color", "", func(clr color.Color) {
 			c.SetBackgroundColor(clr)
@@ -235,12 +311,20 @@ func (c ConfigT) ShowPreferencesDialog(tabs *LifeTabs, clk *DisplayUpdateClock)
 	entries := []*widget.FormItem{
 		widget.NewFormItem("Saved Generations", historySizeEntry),
 		widget.NewFormItem("Auto-zoom enabled by default", autoZoomDefaultCheck),
+		widget.NewFormItem("Still lifes and oscillators", pauseWhenSettledCheck),
+		widget.NewFormItem("On startup", restoreSessionCheck),
 		widget.NewFormItem("Diplay refresh rate", displayRefreshRateSelector),
 		widget.NewFormItem("Mouse wheel function", scrollAsZoomRadioGroup),
 		widget.NewFormItem("Paused Cell Color", pausedColorPickerButton),
 		widget.NewFormItem("Running Cell Color", runningColorPickerButton),
 		widget.NewFormItem("Editing Cell Color", editColorPickerButton),
-		widget.NewFormItem("Background Color", backgroundColorPickerButton)}
+		widget.NewFormItem("Differing Cell Color", diffColorPickerButton),
+		widget.NewFormItem("Background Color", backgroundColorPickerButton),
+		widget.NewFormItem("Grid Color", gridColorPickerButton),
+		widget.NewFormItem("Major grid lines every", gridSpacingEntry)}
+	if !fyne.CurrentDevice().IsMobile() {
+		entries = append(entries, widget.NewFormItem("Shortcuts", shortcutsButton))
+	}
 
 	dialog.ShowForm("Preferences", "Save", "Cancel", entries, func(save bool) {
 		if save {
@@ -258,6 +342,8 @@ func (c ConfigT) ShowPreferencesDialog(tabs *LifeTabs, clk *DisplayUpdateClock)
 				}
 			}
 			c.SetAutoZoomDefault(autoZoomDefaultCheck.Checked)
+			c.SetPauseWhenSettled(pauseWhenSettledCheck.Checked)
+			c.SetRestoreSession(restoreSessionCheck.Checked)
 			if displayRefreshRateSelector.SelectedIndex() == 0 {
 				c.SetDisplayRefreshRate(30)
 			} else {
@@ -265,6 +351,10 @@ func (c ConfigT) ShowPreferencesDialog(tabs *LifeTabs, clk *DisplayUpdateClock)
 			}
 			clk.DisplayUpdateHz = c.DisplayRefreshRate()
 			c.SetScrollAsZoom(scrollAsZoomRadioGroup.Selected == "zoom")
+			if spacing, err := strconv.Atoi(gridSpacingEntry.Text); err == nil {
+				c.SetGridSpacing(spacing)
+			}
+			tabs.RedrawAll()
 		}
 	}, mainWindow)
 }
@@ -0,0 +1,398 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"math/bits"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pneumaticdeath/golife"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Recording animations of a pattern as it runs.  The frames are drawn
+// with an ImageExport, the same way as exported still images, from a copy
+// of the pattern so the sim on screen isn't disturbed.  Every frame uses
+// the same palette, which GIF needs anyway, and which keeps every frame
+// of an APNG encoding the same way.  Getting to the first generation can
+// be a long way, so it's done with HashLife when the rule allows, and the
+// recording can be canceled along the way.
+
+const (
+	maxRecordFrames    = 1000
+	maxRecordPixels    = 500000000 // total over all the frames, since they're all held in memory
+	recordHashLifeJump = 1000      // getting further ahead than this goes by HashLife
+)
+
+var errRecordingCanceled = errors.New("Recording canceled")
+
+type Recording struct {
+	Start, End int         // first and last generations to record
+	FPS        int         // frames per second
+	AutoZoom   bool        // grow the viewport to fit the pattern, like the sim's auto zoom
+	Export     ImageExport // colors, glyph style, cell size and the viewport when it's fixed
+	frameBoxes [][2]golife.Cell
+}
+
+// advanceTo runs the pattern forward to the generation, calling keepGoing
+// every so often and giving up if it returns false.
+func advanceTo(pattern *Pattern, generation int, keepGoing func() bool) bool {
+	lastCheck := time.Now()
+	checkIn := func() bool {
+		if time.Since(lastCheck) < fastForwardCheckInterval {
+			return true
+		}
+		lastCheck = time.Now()
+		return keepGoing()
+	}
+	if !pattern.Rule.IsGenerations() && generation-pattern.Game.Generation > recordHashLifeJump {
+		hashLife := NewHashLife(pattern.Rule)
+		hashLife.SetPopulation(pattern.Game.Population)
+		for pattern.Game.Generation < generation {
+			stepLog := min(maxHashLifeStepLog, bits.Len(uint(generation-pattern.Game.Generation))-1)
+			hashLife.Step(stepLog)
+			pattern.Game.Generation += 1 << stepLog
+			if !checkIn() {
+				return false
+			}
+		}
+		pattern.Game.Population = hashLife.Population()
+		return true
+	}
+	for pattern.Game.Generation < generation {
+		pattern.Dying = pattern.Rule.Next(pattern.Game, pattern.Dying)
+		if !checkIn() {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Recording) check(current int) error {
+	if r.Start < current {
+		return fmt.Errorf("Recording can only start at the current generation (%d) or later", current)
+	}
+	if r.End < r.Start {
+		return errors.New("The last generation is before the first one")
+	}
+	if r.End-r.Start+1 > maxRecordFrames {
+		return fmt.Errorf("Can't record more than %d frames", maxRecordFrames)
+	}
+	if r.FPS < 1 || r.FPS > 100 {
+		return errors.New("Frame rate must be between 1 and 100")
+	}
+	return nil
+}
+
+// Frames runs a copy of the pattern through the generations, drawing each
+// one.  The progress function is called after each frame, and every so
+// often on the way to the first one, and the recording is canceled if it
+// returns false.
+func (r *Recording) Frames(pattern *Pattern, progress func(done, total int) bool) ([]*image.Paletted, error) {
+	if err := r.check(pattern.Game.Generation); err != nil {
+		return nil, err
+	}
+	total := r.End - r.Start + 1
+	if progress == nil {
+		progress = func(int, int) bool { return true }
+	}
+	keepGoing := func() bool { return progress(0, total) }
+
+	// With auto zoom the viewport only ever grows, so we need a first pass
+	// to find how big it gets, which sets the size of the image.
+	r.frameBoxes = make([][2]golife.Cell, 0, total)
+	if r.AutoZoom {
+		scout := pattern.Copy()
+		scout.Game.SetHistorySize(0)
+		if !advanceTo(scout, r.Start, keepGoing) {
+			return nil, errRecordingCanceled
+		}
+		minCell, maxCell := PatternBoundingBox(scout)
+		for generation := r.Start; generation <= r.End; generation++ {
+			if !advanceTo(scout, generation, keepGoing) {
+				return nil, errRecordingCanceled
+			}
+			if scout.Game.Size()+len(scout.Dying) > 0 {
+				boxMin, boxMax := PatternBoundingBox(scout)
+				minCell = golife.Cell{X: min(minCell.X, boxMin.X), Y: min(minCell.Y, boxMin.Y)}
+				maxCell = golife.Cell{X: max(maxCell.X, boxMax.X), Y: max(maxCell.Y, boxMax.Y)}
+			}
+			r.frameBoxes = append(r.frameBoxes, [2]golife.Cell{minCell, maxCell})
+		}
+		r.Export.Min, r.Export.Max = minCell, maxCell
+	}
+
+	if err := r.Export.check(); err != nil {
+		return nil, err
+	}
+	width, height := r.Export.ImageSize()
+	if width*height*total > maxRecordPixels {
+		return nil, errors.New("Animation would be too big, try fewer frames or fewer pixels per cell")
+	}
+
+	palette := color.Palette{r.Export.Background, r.Export.GridColor}
+	for _, clr := range r.Export.StateColors[1:] {
+		palette = append(palette, clr)
+	}
+
+	frames := make([]*image.Paletted, 0, total)
+	sim := pattern.Copy()
+	sim.Game.SetHistorySize(0)
+	for generation := r.Start; generation <= r.End; generation++ {
+		if !advanceTo(sim, generation, keepGoing) {
+			return nil, errRecordingCanceled
+		}
+		frameExport := r.Export
+		if r.AutoZoom {
+			// pick the biggest whole number of pixels per cell that fits
+			box := r.frameBoxes[generation-r.Start]
+			frameExport.Min, frameExport.Max = box[0], box[1]
+			frameExport.CellSize = max(1, min(width/int(box[1].X-box[0].X+1), height/int(box[1].Y-box[0].Y+1)))
+		}
+		img, err := frameExport.Render(sim)
+		if err != nil {
+			return nil, err
+		}
+		frame := image.NewPaletted(image.Rect(0, 0, width, height), palette)
+		offset := image.Pt((width-img.Bounds().Dx())/2, (height-img.Bounds().Dy())/2)
+		draw.Draw(frame, img.Bounds().Add(offset), img, image.Point{}, draw.Src)
+		frames = append(frames, frame)
+		if !progress(len(frames), total) {
+			return nil, errRecordingCanceled
+		}
+	}
+	return frames, nil
+}
+
+// WriteGIF writes the frames as an animated GIF that loops forever
+func WriteGIF(frames []*image.Paletted, fps int, writer io.Writer) error {
+	anim := &gif.GIF{Image: frames, Delay: make([]int, len(frames))}
+	for index := range anim.Delay {
+		anim.Delay[index] = max(1, 100/fps) // in hundredths of a second
+	}
+	return gif.EncodeAll(writer, anim)
+}
+
+// WriteAPNG writes the frames as an animated PNG.  The standard library
+// doesn't know about APNG, but it's just a PNG with a few extra chunks:
+// each frame is encoded as a PNG, and its image data is copied into the
+// animation, as IDAT chunks for the first frame and fdAT for the rest.
+func WriteAPNG(frames []*image.Paletted, fps int, writer io.Writer) error {
+	if len(frames) == 0 {
+		return errors.New("No frames to write")
+	}
+	out := bufio.NewWriter(writer)
+	out.WriteString("\x89PNG\r\n\x1a\n")
+
+	sequence := uint32(0)
+	bounds := frames[0].Bounds()
+	for index, frame := range frames {
+		var encoded bytes.Buffer
+		if err := png.Encode(&encoded, frame); err != nil {
+			return err
+		}
+		chunks, err := pngChunks(encoded.Bytes())
+		if err != nil {
+			return err
+		}
+
+		if index == 0 {
+			// everything before the image data (IHDR, PLTE, tRNS) comes from the first frame
+			for _, chunk := range chunks {
+				if chunk.kind == "IDAT" {
+					break
+				}
+				writePNGChunk(out, chunk.kind, chunk.data)
+			}
+			actl := make([]byte, 8)
+			binary.BigEndian.PutUint32(actl[0:], uint32(len(frames)))
+			binary.BigEndian.PutUint32(actl[4:], 0) // loop forever
+			writePNGChunk(out, "acTL", actl)
+		}
+
+		fctl := make([]byte, 26)
+		binary.BigEndian.PutUint32(fctl[0:], sequence)
+		binary.BigEndian.PutUint32(fctl[4:], uint32(bounds.Dx()))
+		binary.BigEndian.PutUint32(fctl[8:], uint32(bounds.Dy()))
+		// the x and y offsets stay 0
+		binary.BigEndian.PutUint16(fctl[20:], 1)
+		binary.BigEndian.PutUint16(fctl[22:], uint16(fps))
+		// dispose and blend ops stay 0, every frame replaces the last one
+		writePNGChunk(out, "fcTL", fctl)
+		sequence++
+
+		for _, chunk := range chunks {
+			if chunk.kind != "IDAT" {
+				continue
+			}
+			if index == 0 {
+				writePNGChunk(out, "IDAT", chunk.data)
+			} else {
+				fdat := make([]byte, 4, 4+len(chunk.data))
+				binary.BigEndian.PutUint32(fdat, sequence)
+				writePNGChunk(out, "fdAT", append(fdat, chunk.data...))
+				sequence++
+			}
+		}
+	}
+	writePNGChunk(out, "IEND", nil)
+	return out.Flush()
+}
+
+type pngChunk struct {
+	kind string
+	data []byte
+}
+
+func pngChunks(encoded []byte) ([]pngChunk, error) {
+	const signatureLength = 8
+	if len(encoded) < signatureLength {
+		return nil, errors.New("PNG is too short")
+	}
+	var chunks []pngChunk
+	rest := encoded[signatureLength:]
+	for len(rest) >= 12 {
+		length := int(binary.BigEndian.Uint32(rest))
+		if len(rest) < length+12 {
+			return nil, errors.New("PNG chunk is truncated")
+		}
+		chunks = append(chunks, pngChunk{kind: string(rest[4:8]), data: rest[8 : 8+length]})
+		rest = rest[length+12:]
+	}
+	return chunks, nil
+}
+
+func writePNGChunk(out *bufio.Writer, kind string, data []byte) {
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header, uint32(len(data)))
+	copy(header[4:], kind)
+	out.Write(header)
+	out.Write(data)
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(kind))
+	crc.Write(data)
+	binary.Write(out, binary.BigEndian, crc.Sum32())
+}
+
+// ShowRecordDialog asks which generations to record and how, then where
+// to save the animation.  The file extension picks between GIF and APNG.
+func ShowRecordDialog(ls *LifeSim) {
+	generation := ls.Game.Generation
+	intValidator := func(text string) error {
+		_, err := strconv.Atoi(text)
+		return err
+	}
+	startEntry := widget.NewEntry()
+	startEntry.SetText(strconv.Itoa(generation))
+	startEntry.Validator = intValidator
+	endEntry := widget.NewEntry()
+	endEntry.SetText(strconv.Itoa(generation + 100))
+	endEntry.Validator = intValidator
+	fpsEntry := widget.NewEntry()
+	fpsEntry.SetText("10")
+	fpsEntry.Validator = intValidator
+	cellSizeEntry := widget.NewEntry()
+	cellSizeEntry.SetText(strconv.Itoa(max(1, int(ls.Scale))))
+	cellSizeEntry.Validator = intValidator
+	viewportSelector := widget.NewRadioGroup([]string{"Fixed (current view)", "Auto-zoom each frame"}, nil)
+	viewportSelector.Required = true
+	if ls.IsAutoZoom() {
+		viewportSelector.SetSelected("Auto-zoom each frame")
+	} else {
+		viewportSelector.SetSelected("Fixed (current view)")
+	}
+
+	formItems := []*widget.FormItem{
+		widget.NewFormItem("From generation", startEntry),
+		widget.NewFormItem("To generation", endEntry),
+		widget.NewFormItem("Frames per second", fpsEntry),
+		widget.NewFormItem("Pixels per cell", cellSizeEntry),
+		widget.NewFormItem("Viewport", viewportSelector),
+	}
+	dialog.ShowForm("Record animation", "Record", "Cancel", formItems, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		recording := &Recording{AutoZoom: viewportSelector.Selected == "Auto-zoom each frame"}
+		recording.Start, _ = strconv.Atoi(startEntry.Text)
+		recording.End, _ = strconv.Atoi(endEntry.Text)
+		recording.FPS, _ = strconv.Atoi(fpsEntry.Text)
+		cellSize, _ := strconv.Atoi(cellSizeEntry.Text)
+		recording.Export = *ls.NewImageExport(false, cellSize, false)
+		pattern := ls.Pattern().Copy()
+		if err := recording.check(pattern.Game.Generation); err != nil {
+			dialog.ShowError(err, mainWindow)
+			return
+		}
+
+		animFilter := &LongExtensionsFileFilter{Extensions: []string{".gif", ".png", ".apng"}}
+		fileSave := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, mainWindow)
+				return
+			}
+			if writer == nil {
+				return
+			}
+			if writer.URI().Scheme() == "file" {
+				Config.SetLastUsedDirURI(writer.URI())
+			}
+			recordToFile(recording, pattern, writer)
+		}, mainWindow)
+		fileSave.SetFilter(animFilter)
+		fileSave.SetFileName("animation.gif")
+		fileSave.SetLocation(Config.LastUsedDirURI())
+		fileSave.Show()
+	}, mainWindow)
+}
+
+// recordToFile draws the frames in the background with a progress dialog
+// up, then writes them out.  Canceling leaves the file empty.
+func recordToFile(recording *Recording, pattern *Pattern, writer fyne.URIWriteCloser) {
+	var canceled atomic.Bool
+	progressBar := widget.NewProgressBar()
+	progress := dialog.NewCustom("Recording...", "Cancel", container.NewPadded(progressBar), mainWindow)
+	progress.SetOnClosed(func() { canceled.Store(true) })
+	progress.Show()
+
+	go func() {
+		defer writer.Close()
+		frames, err := recording.Frames(pattern, func(done, total int) bool {
+			fyne.Do(func() { progressBar.SetValue(float64(done) / float64(total)) })
+			return !canceled.Load()
+		})
+		if err == errRecordingCanceled {
+			err = nil
+		} else if err == nil {
+			name := strings.ToLower(writer.URI().Name())
+			if strings.HasSuffix(name, ".png") || strings.HasSuffix(name, ".apng") {
+				err = WriteAPNG(frames, recording.FPS, writer)
+			} else {
+				err = WriteGIF(frames, recording.FPS, writer)
+			}
+		}
+		fyne.Do(func() {
+			progress.Hide()
+			if err != nil {
+				dialog.ShowError(err, mainWindow)
+			}
+		})
+	}()
+}
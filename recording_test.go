@@ -0,0 +1,61 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/pneumaticdeath/golife"
+)
+
+// the R-pentomino, which keeps changing for over a thousand generations
+var testRPentomino = []golife.Cell{{X: 1, Y: 0}, {X: 2, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 2}}
+
+func TestAdvanceToWithHashLife(t *testing.T) {
+	target := recordHashLifeJump + 300
+	jumped := newTestPattern(testRPentomino...)
+	if !advanceTo(jumped, target, func() bool { return true }) {
+		t.Fatal("gave up")
+	}
+	stepped := newTestPattern(testRPentomino...)
+	for stepped.Game.Generation < target {
+		stepped.Game.Population = stepped.Rule.Step(stepped.Game.Population)
+		stepped.Game.Generation++
+	}
+	if jumped.Game.Generation != target {
+		t.Errorf("got to generation %d, want %d", jumped.Game.Generation, target)
+	}
+	if !slices.Equal(populationCells(jumped.Game.Population), populationCells(stepped.Game.Population)) {
+		t.Error("HashLife got to a different population than stepping")
+	}
+}
+
+func TestRecordingFarAhead(t *testing.T) {
+	glider := newTestPattern(testGlider...)
+	start := 1000000000
+	recording := &Recording{Start: start, End: start + 3, FPS: 10, AutoZoom: true,
+		Export: *newTestExport(golife.Cell{}, golife.Cell{}, 2, false)}
+	frames, err := recording.Frames(glider, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 4 {
+		t.Errorf("got %d frames, want 4", len(frames))
+	}
+	if glider.Game.Generation != 0 {
+		t.Error("recording moved the pattern on")
+	}
+}
+
+func TestRecordingCanceled(t *testing.T) {
+	brain, err := ParseRule("B2/S/C3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pattern := newTestPattern(testRPentomino...)
+	pattern.Rule = brain
+	recording := &Recording{Start: 1000000000, End: 1000000001, FPS: 10,
+		Export: *newTestExport(golife.Cell{X: -10, Y: -10}, golife.Cell{X: 10, Y: 10}, 2, false)}
+	if _, err := recording.Frames(pattern, func(int, int) bool { return false }); err != errRecordingCanceled {
+		t.Errorf("got error %v, want the recording to be canceled", err)
+	}
+}
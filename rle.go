@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pneumaticdeath/golife"
+)
+
+// The golife library only understands RLE files for Conway's Life (it
+// refuses anything with a different rule in the header, and always
+// writes "rule = b3/s23"), so we have our own RLE reader and writer that
+// carry the rule along with the game.  For Generations rules the cell
+// states are written as letters: "." is dead, "A" is alive and "B"
+// onwards are the dying states.
+
+const rleMaxLineLength = 70
+
+// A Pattern is a game along with the rule it runs under and, for
+// Generations rules, the cells that are dying.
+type Pattern struct {
+	Game  *golife.Game
+	Rule  Rule
+	Dying DyingCells
+}
+
+// Copy makes a copy of the pattern that can be changed independently
+func (p *Pattern) Copy() *Pattern {
+	game := p.Game.Copy()
+	// golife's Copy shares the history's backing array, so without this
+	// stepping either game would write over the other's history
+	game.History = slices.Clip(game.History)
+	return &Pattern{Game: game, Rule: p.Rule, Dying: p.Dying.Copy()}
+}
+
+// A PatternReader parses a pattern file.
+type PatternReader func(io.Reader) (*Pattern, error)
+
+// FindPatternReader picks a reader based on the file name, like
+// golife.FindReader but using our RLE reader so non-Conway rules work.
+func FindPatternReader(filename string) PatternReader {
+	if strings.HasSuffix(filename, ".rle") || strings.HasSuffix(filename, ".rle.txt") {
+		return ReadRLE
+	}
+	if strings.HasSuffix(filename, ".mc") || strings.HasSuffix(filename, ".mc.gz") {
+		return ReadMacrocell
+	}
+	lifeReader := golife.FindReader(filename)
+	return func(reader io.Reader) (*Pattern, error) {
+		game, err := lifeReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		return &Pattern{Game: game, Rule: ConwayRule}, nil
+	}
+}
+
+// A PatternWriter writes a pattern file.
+type PatternWriter func(*Pattern, io.Writer) error
+
+// FindPatternWriter picks a writer based on the file name, defaulting to
+// RLE.  Files ending in .gz are compressed.
+func FindPatternWriter(filename string) PatternWriter {
+	if strings.HasSuffix(filename, ".mc.gz") {
+		return func(pattern *Pattern, writer io.Writer) error {
+			zipped := gzip.NewWriter(writer)
+			err := WriteMacrocell(pattern, zipped)
+			if err != nil {
+				return err
+			}
+			return zipped.Close()
+		}
+	}
+	if strings.HasSuffix(filename, ".mc") {
+		return WriteMacrocell
+	}
+	return WriteRLE
+}
+
+// ReadRLE reads a pattern in RLE format, including the rule in its header.
+// Patterns without a header are assumed to be Conway's Life.
+func ReadRLE(reader io.Reader) (*Pattern, error) {
+	pattern := &Pattern{Game: golife.NewGame(), Rule: ConwayRule}
+	game := pattern.Game
+
+	contents, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var x, y golife.Coord
+	count := 0
+	done := false
+	for _, line := range strings.Split(string(contents), "\n") {
+		line = strings.TrimRight(line, "\r")
+		switch {
+		case strings.HasPrefix(line, "#N "):
+			game.Name = strings.TrimSpace(strings.TrimPrefix(line, "#N "))
+		case strings.HasPrefix(line, "#O "):
+			game.Author = strings.TrimSpace(strings.TrimPrefix(line, "#O "))
+		case strings.HasPrefix(line, "#r "):
+			pattern.Rule, err = ParseRule(strings.TrimPrefix(line, "#r "))
+			if err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(line, "#"):
+			game.Comments = append(game.Comments, strings.TrimPrefix(line, "#"))
+		case strings.Contains(line, "="):
+			for _, field := range strings.Split(line, ",") {
+				key, value, found := strings.Cut(field, "=")
+				if found && strings.TrimSpace(key) == "rule" {
+					pattern.Rule, err = ParseRule(value)
+					if err != nil {
+						return nil, err
+					}
+				}
+			}
+		default:
+			for _, c := range strings.TrimSpace(line) {
+				n := max(count, 1)
+				switch {
+				case c >= '0' && c <= '9':
+					count = count*10 + int(c-'0')
+					continue
+				case c == '$':
+					y += golife.Coord(n)
+					x = 0
+				case c == 'b' || c == '.':
+					x += golife.Coord(n)
+				case c == 'o' || (c >= 'A' && c <= 'X'):
+					state := uint8(1)
+					if pattern.Rule.IsGenerations() && c != 'o' {
+						state = uint8(c-'A') + 1
+						if int(state) >= pattern.Rule.States {
+							return nil, fmt.Errorf("Cell state %c is out of range for rule %s", c, pattern.Rule)
+						}
+					}
+					for i := 0; i < n; i++ {
+						if state == 1 {
+							game.AddCell(golife.Cell{X: x, Y: y})
+						} else {
+							if pattern.Dying == nil {
+								pattern.Dying = make(DyingCells)
+							}
+							pattern.Dying[golife.Cell{X: x, Y: y}] = state
+						}
+						x++
+					}
+				case c == '!':
+					done = true
+				case c == ' ' || c == '\t':
+				default:
+					return nil, fmt.Errorf("Got unknown code point %c in RLE", c)
+				}
+				count = 0
+				if done {
+					break
+				}
+			}
+		}
+		if done {
+			break
+		}
+	}
+
+	return pattern, nil
+}
+
+type rleRun struct {
+	count  int
+	symbol byte
+}
+
+func appendRun(runs []rleRun, count int, symbol byte) []rleRun {
+	if count <= 0 {
+		return runs
+	}
+	if len(runs) > 0 && runs[len(runs)-1].symbol == symbol {
+		runs[len(runs)-1].count += count
+		return runs
+	}
+	return append(runs, rleRun{count, symbol})
+}
+
+// encodeRLE turns the cells into a list of runs, with the upper left
+// corner of the bounding box as the origin.  The symbols map gives the
+// character to use for each non-blank cell.
+func encodeRLE(symbols map[golife.Cell]byte, minCell golife.Cell, blank byte) []rleRun {
+	cells := make(golife.CellList, 0, len(symbols))
+	for cell := range symbols {
+		cells = append(cells, cell)
+	}
+	sort.Sort(cells)
+
+	runs := make([]rleRun, 0, 100)
+	x, y := minCell.X, minCell.Y
+	for _, cell := range cells {
+		if cell.Y > y {
+			runs = appendRun(runs, int(cell.Y-y), '$')
+			x, y = minCell.X, cell.Y
+		}
+		runs = appendRun(runs, int(cell.X-x), blank)
+		runs = appendRun(runs, 1, symbols[cell])
+		x = cell.X + 1
+	}
+	return append(runs, rleRun{1, '!'})
+}
+
+// WriteRLE writes the pattern in RLE format with its rule in the header.
+func WriteRLE(pattern *Pattern, writer io.Writer) error {
+	game := pattern.Game
+
+	live, blank := byte('o'), byte('b')
+	if pattern.Rule.IsGenerations() {
+		live, blank = 'A', '.'
+	}
+	symbols := make(map[golife.Cell]byte, game.Size()+len(pattern.Dying))
+	for cell := range game.Population {
+		symbols[cell] = live
+	}
+	if pattern.Rule.IsGenerations() {
+		for cell, state := range pattern.Dying {
+			if int(state) < pattern.Rule.States {
+				symbols[cell] = 'A' + state - 1
+			}
+		}
+	}
+
+	// No cells is just an empty pattern at the origin
+	minCell, maxCell := golife.Cell{}, golife.Cell{X: -1, Y: -1}
+	first := true
+	for cell := range symbols {
+		if first {
+			minCell, maxCell, first = cell, cell, false
+		}
+		minCell = golife.Cell{X: min(minCell.X, cell.X), Y: min(minCell.Y, cell.Y)}
+		maxCell = golife.Cell{X: max(maxCell.X, cell.X), Y: max(maxCell.Y, cell.Y)}
+	}
+
+	out := bufio.NewWriter(writer)
+	if game.Name != "" {
+		fmt.Fprintf(out, "#N %s\n", game.Name)
+	}
+	if game.Author != "" {
+		fmt.Fprintf(out, "#O %s\n", game.Author)
+	}
+	for _, comment := range game.Comments {
+		fmt.Fprintf(out, "#%s\n", strings.TrimSuffix(strings.TrimPrefix(comment, "#"), "\n"))
+	}
+	if game.Filename != "" {
+		fmt.Fprintf(out, "#C originally loaded from \"%s\"\n", game.Filename)
+	}
+	if game.Generation > 0 {
+		fmt.Fprintf(out, "#C at generation %d\n", game.Generation)
+	}
+	if len(symbols) > 0 {
+		fmt.Fprintf(out, "#C bounded by %d,%d -> %d,%d\n", minCell.X, minCell.Y, maxCell.X, maxCell.Y)
+	}
+	fmt.Fprintf(out, "x = %d, y = %d, rule = %s\n", maxCell.X-minCell.X+1, maxCell.Y-minCell.Y+1, pattern.Rule)
+
+	var line strings.Builder
+	for _, run := range encodeRLE(symbols, minCell, blank) {
+		token := string(run.symbol)
+		if run.count > 1 {
+			token = strconv.Itoa(run.count) + token
+		}
+		if line.Len()+len(token) > rleMaxLineLength {
+			fmt.Fprintln(out, line.String())
+			line.Reset()
+		}
+		line.WriteString(token)
+	}
+	fmt.Fprintln(out, line.String())
+
+	return out.Flush()
+}
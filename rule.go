@@ -0,0 +1,319 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pneumaticdeath/golife"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// A Rule describes an outer totalistic cellular automaton in the usual
+// B/S notation, e.g. Conway's Life is B3/S23: a dead cell with exactly
+// three live neighbors is born, and a live cell with two or three live
+// neighbors survives.  The Birth and Survive fields are bitmasks where
+// bit N is set if N neighbors causes a birth (or survival).
+//
+// Rules from the "Generations" family have more than two states.  A live
+// cell that doesn't survive goes into state 2 and then counts up through
+// the dying states until it reaches States and is dead.  Dying cells
+// don't count as neighbors and nothing can be born on top of them.
+// Generations rules are written with a third field, e.g. Brian's Brain
+// is B2/S/C3 (or /2/3 in S/B/C notation).
+
+type Rule struct {
+	Birth   uint16
+	Survive uint16
+	States  int // 2 for ordinary life-like rules
+}
+
+// Letters A through X are used for cell states in RLE files, so that's
+// as many states as we can save.
+const maxRuleStates = 25
+
+var ConwayRule = Rule{Birth: 1 << 3, Survive: 1<<2 | 1<<3, States: 2}
+
+type RulePreset struct {
+	Name string
+	Rule string
+}
+
+// RulePresets is the list of well known rules offered in the rule dialog
+var RulePresets []RulePreset = []RulePreset{
+	{"Conway's Life", "B3/S23"},
+	{"HighLife", "B36/S23"},
+	{"Day & Night", "B3678/S34678"},
+	{"Seeds", "B2/S"},
+	{"Life without Death", "B3/S012345678"},
+	{"2x2", "B36/S125"},
+	{"Replicator", "B1357/S1357"},
+	{"Maze", "B3/S12345"},
+	{"Diamoeba", "B35678/S5678"},
+	{"Morley", "B368/S245"},
+	{"Anneal", "B4678/S35678"},
+	{"Brian's Brain", "B2/S/C3"},
+	{"Star Wars", "B2/S345/C4"},
+	{"Bloomerang", "B34678/S234/C24"},
+	{"Frogs", "B34/S12/C3"},
+	{"Sticks", "B2/S3456/C6"},
+}
+
+// ParseRule parses a rule in either B/S notation ("B36/S23") or the older
+// S/B notation ("23/36").  Case and the separating slash are optional.
+// Generations rules add a number of states, either as "/C3" (or "/G3")
+// in B/S notation, or as a third field in S/B notation ("/2/3").
+func ParseRule(ruleStr string) (Rule, error) {
+	rule := Rule{States: 2}
+	str := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(ruleStr), " ", ""))
+	if str == "" {
+		return rule, errors.New("Empty rule")
+	}
+
+	parseErr := fmt.Errorf("Unable to parse rule %s", ruleStr)
+	statesStr := ""
+	if strings.ContainsAny(str, "BS") {
+		var current *uint16
+		inStates := false
+		for _, c := range str {
+			switch {
+			case c == 'B':
+				current, inStates = &rule.Birth, false
+			case c == 'S':
+				current, inStates = &rule.Survive, false
+			case c == 'C' || c == 'G':
+				current, inStates = nil, true
+			case c == '/':
+				current, inStates = nil, false
+			case c >= '0' && c <= '9' && inStates:
+				statesStr += string(c)
+			case c >= '0' && c <= '8' && current != nil:
+				*current |= 1 << (c - '0')
+			default:
+				return rule, parseErr
+			}
+		}
+	} else {
+		fields := strings.Split(str, "/")
+		if len(fields) < 2 || len(fields) > 3 {
+			return rule, parseErr
+		}
+		for index, masks := range []*uint16{&rule.Survive, &rule.Birth} {
+			for _, c := range fields[index] {
+				if c < '0' || c > '8' {
+					return rule, parseErr
+				}
+				*masks |= 1 << (c - '0')
+			}
+		}
+		if len(fields) == 3 {
+			statesStr = fields[2]
+		}
+	}
+
+	if statesStr != "" {
+		states, err := strconv.Atoi(statesStr)
+		if err != nil {
+			return rule, parseErr
+		}
+		if states < 2 || states > maxRuleStates {
+			return rule, fmt.Errorf("Rules must have between 2 and %d states", maxRuleStates)
+		}
+		rule.States = states
+	}
+
+	if rule.Birth&1 != 0 {
+		return rule, errors.New("Rules where cells are born with no neighbors (B0) aren't supported")
+	}
+
+	return rule, nil
+}
+
+func maskDigits(mask uint16) string {
+	var digits strings.Builder
+	for n := 0; n <= 8; n++ {
+		if mask&(1<<n) != 0 {
+			digits.WriteByte(byte('0' + n))
+		}
+	}
+	return digits.String()
+}
+
+// String returns the rule in canonical B/S notation
+func (r Rule) String() string {
+	if r.IsGenerations() {
+		return fmt.Sprintf("B%s/S%s/C%d", maskDigits(r.Birth), maskDigits(r.Survive), r.States)
+	}
+	return "B" + maskDigits(r.Birth) + "/S" + maskDigits(r.Survive)
+}
+
+// Name returns the well known name of the rule, or the rule string if it
+// doesn't have one.
+func (r Rule) Name() string {
+	for _, preset := range RulePresets {
+		if preset.Rule == r.String() {
+			return preset.Name
+		}
+	}
+	return r.String()
+}
+
+func (r Rule) IsConway() bool {
+	return r == ConwayRule
+}
+
+// IsGenerations reports whether the rule has dying states
+func (r Rule) IsGenerations() bool {
+	return r.States > 2
+}
+
+// Step calculates the next generation of the population under this rule.
+func (r Rule) Step(current golife.Population) golife.Population {
+	nextgen := make(golife.Population, len(current))
+	neighbor_count := make(map[golife.Cell]int8, len(current)*4)
+	for cell := range current {
+		x, y := cell.X, cell.Y
+		neighbor_count[golife.Cell{X: x - 1, Y: y - 1}]++
+		neighbor_count[golife.Cell{X: x, Y: y - 1}]++
+		neighbor_count[golife.Cell{X: x + 1, Y: y - 1}]++
+		neighbor_count[golife.Cell{X: x - 1, Y: y}]++
+		neighbor_count[golife.Cell{X: x + 1, Y: y}]++
+		neighbor_count[golife.Cell{X: x - 1, Y: y + 1}]++
+		neighbor_count[golife.Cell{X: x, Y: y + 1}]++
+		neighbor_count[golife.Cell{X: x + 1, Y: y + 1}]++
+	}
+
+	for cell, count := range neighbor_count {
+		if current[cell] {
+			if r.Survive&(1<<count) != 0 {
+				nextgen[cell] = true
+			}
+		} else if r.Birth&(1<<count) != 0 {
+			nextgen[cell] = true
+		}
+	}
+
+	if r.Survive&1 != 0 {
+		// isolated cells never show up in the neighbor counts
+		for cell := range current {
+			if neighbor_count[cell] == 0 {
+				nextgen[cell] = true
+			}
+		}
+	}
+
+	return nextgen
+}
+
+// StepGenerations calculates the next generation of a Generations rule,
+// where cells that don't survive go through the dying states.
+func (r Rule) StepGenerations(current golife.Population, dying DyingCells) (golife.Population, DyingCells) {
+	nextgen := make(golife.Population, len(current))
+	nextDying := make(DyingCells, len(dying)+len(current)/4)
+	neighbor_count := make(map[golife.Cell]int8, len(current)*4)
+	for cell := range current {
+		for dy := golife.Coord(-1); dy <= 1; dy++ {
+			for dx := golife.Coord(-1); dx <= 1; dx++ {
+				if dx != 0 || dy != 0 {
+					neighbor_count[golife.Cell{X: cell.X + dx, Y: cell.Y + dy}]++
+				}
+			}
+		}
+	}
+
+	for cell, count := range neighbor_count {
+		if !current[cell] && dying[cell] == 0 && r.Birth&(1<<count) != 0 {
+			nextgen[cell] = true
+		}
+	}
+
+	for cell := range current {
+		if r.Survive&(1<<neighbor_count[cell]) != 0 {
+			nextgen[cell] = true
+		} else {
+			nextDying[cell] = 2
+		}
+	}
+
+	for cell, state := range dying {
+		if int(state)+1 < r.States {
+			nextDying[cell] = state + 1
+		}
+	}
+
+	return nextgen, nextDying
+}
+
+// Next moves the game on to the next generation under this rule, and
+// returns the new dying cells (which is always nil unless this is a
+// Generations rule).  The history is handled the same way as
+// golife.Game.Next() does it.
+func (r Rule) Next(game *golife.Game, dying DyingCells) DyingCells {
+	if r.IsConway() {
+		game.Next()
+		return nil
+	}
+	if game.HistorySize != 0 {
+		if game.History == nil {
+			game.History = make([]golife.Population, 0, max(game.HistorySize, 10))
+		}
+		if game.HistorySize > 0 && len(game.History) >= game.HistorySize {
+			game.History = game.History[len(game.History)-game.HistorySize+1:]
+		}
+		game.History = append(game.History, game.Population)
+	} else {
+		game.History = nil
+	}
+	var nextDying DyingCells
+	if r.IsGenerations() {
+		game.Population, nextDying = r.StepGenerations(game.Population, dying)
+	} else {
+		game.Population = r.Step(game.Population)
+	}
+	game.Generation += 1
+	return nextDying
+}
+
+// ShowRuleDialog lets the user pick the rule for the sim in the container
+func ShowRuleDialog(lc *LifeContainer) {
+	ruleEntry := widget.NewEntry()
+	ruleEntry.SetText(lc.Sim.Rule.String())
+	ruleEntry.Validator = func(text string) error {
+		_, err := ParseRule(text)
+		return err
+	}
+
+	presetNames := make([]string, 0, len(RulePresets))
+	for _, preset := range RulePresets {
+		presetNames = append(presetNames, preset.Name)
+	}
+	presetSelector := widget.NewSelect(presetNames, func(selection string) {
+		for _, preset := range RulePresets {
+			if preset.Name == selection {
+				ruleEntry.SetText(preset.Rule)
+			}
+		}
+	})
+	presetSelector.PlaceHolder = "Custom"
+	if name := lc.Sim.Rule.Name(); name != lc.Sim.Rule.String() {
+		presetSelector.SetSelected(name)
+	}
+
+	formItems := []*widget.FormItem{
+		widget.NewFormItem("Presets", presetSelector),
+		widget.NewFormItem("Rule", ruleEntry),
+	}
+	dialog.ShowForm("Set rule", "Set", "Cancel", formItems, func(set bool) {
+		if set {
+			rule, err := ParseRule(ruleEntry.Text)
+			if err != nil {
+				dialog.ShowError(err, mainWindow)
+				return
+			}
+			lc.SetRule(rule)
+		}
+	}, mainWindow)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pneumaticdeath/golife"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string // the canonical form, or empty if it shouldn't parse
+	}{
+		{"B3/S23", "B3/S23"},
+		{"b3/s23", "B3/S23"},
+		{"S23/B3", "B3/S23"},
+		{"B3S23", "B3/S23"},
+		{" B36 / S23 ", "B36/S23"},
+		{"23/3", "B3/S23"},
+		{"23/36", "B36/S23"},
+		{"/2", "B2/S"},
+		{"B2/S", "B2/S"},
+		{"B2/S/C3", "B2/S/C3"},
+		{"b2/s/g3", "B2/S/C3"},
+		{"/2/3", "B2/S/C3"},
+		{"345/2/4", "B2/S345/C4"},
+		{"", ""},
+		{"B9/S23", ""},
+		{"B3/S2x", ""},
+		{"23/39", ""},
+		{"23", ""},
+		{"1/2/3/4", ""},
+		{"B2/S/C1", ""},
+		{"B2/S/C300", ""},
+		{"B03/S23", ""},
+		{"0123/01", ""},
+	}
+	for _, test := range tests {
+		rule, err := ParseRule(test.input)
+		switch {
+		case test.want == "" && err == nil:
+			t.Errorf("ParseRule(%q) = %s, want an error", test.input, rule)
+		case test.want != "" && err != nil:
+			t.Errorf("ParseRule(%q) failed: %v", test.input, err)
+		case test.want != "" && rule.String() != test.want:
+			t.Errorf("ParseRule(%q) = %s, want %s", test.input, rule, test.want)
+		}
+	}
+}
+
+func TestParseRuleRejectsB0(t *testing.T) {
+	_, err := ParseRule("B03/S23")
+	if err == nil || !strings.Contains(err.Error(), "B0") {
+		t.Errorf("got error %v, want one about B0", err)
+	}
+}
+
+func TestRuleNames(t *testing.T) {
+	for _, preset := range RulePresets {
+		rule, err := ParseRule(preset.Rule)
+		if err != nil {
+			t.Errorf("%s: %v", preset.Name, err)
+			continue
+		}
+		if rule.String() != preset.Rule || rule.Name() != preset.Name {
+			t.Errorf("%s comes back as %s, named %s", preset.Rule, rule, rule.Name())
+		}
+	}
+}
+
+func TestRuleRLEHeader(t *testing.T) {
+	for _, ruleStr := range []string{"B36/S23", "B2/S/C3", "B3/S23"} {
+		rule, err := ParseRule(ruleStr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pattern := newTestPattern(golife.Cell{X: 0, Y: 0}, golife.Cell{X: 1, Y: 0})
+		pattern.Rule = rule
+		var rle strings.Builder
+		if err := WriteRLE(pattern, &rle); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(rle.String(), "rule = "+ruleStr) {
+			t.Errorf("RLE header doesn't have the rule:\n%s", rle.String())
+		}
+		read, err := ReadRLE(strings.NewReader(rle.String()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if read.Rule != rule {
+			t.Errorf("%s came back from RLE as %s", rule, read.Rule)
+		}
+	}
+
+	// other programs write the rule in lower case, or S/B order
+	for header, want := range map[string]string{
+		"x = 2, y = 1, rule = b36/s23":   "B36/S23",
+		"x = 2, y = 1, rule = 23/36":     "B36/S23",
+		"x = 2, y = 1, rule = B2/S/C3":   "B2/S/C3",
+		"x = 2, y = 1":                   "B3/S23",
+		"x = 2, y = 1, rule = 345/2/4":   "B2/S345/C4",
+		"x = 2, y = 1, rule = B3/S23:T0": "",
+	} {
+		read, err := ReadRLE(strings.NewReader(header + "\n2o!\n"))
+		if want == "" {
+			if err == nil {
+				t.Errorf("%q: read rule %s, want an error", header, read.Rule)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", header, err)
+			continue
+		}
+		if read.Rule.String() != want {
+			t.Errorf("%q: got rule %s, want %s", header, read.Rule, want)
+		}
+	}
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+)
+
+// Saved games live in the app's storage, one RLE file per game, with a
+// small index of their names so the menus don't need to read every
+// pattern.  The patterns themselves are only read when they're needed.
+// Older versions kept them all in the preferences, and they're moved
+// over the first time the store is opened.
+
+const (
+	savedGamesIndexFile   = "saved_games.json"
+	savedGamesIndexBackup = "saved_games.json.new" // a copy written first, in case writing the index fails part way
+)
+
+// A SavedGame is a pattern saved under a name, along with when it was
+// saved.  Games saved by older versions don't have a date.
+type SavedGame struct {
+	Name    string
+	File    string // the file in the app's storage holding the pattern
+	Saved   time.Time
+	Cells   int
+	pattern *Pattern // read from File when it's first opened
+}
+
+type SavedGameStore struct {
+	storage fyne.Storage
+	games   map[string]*SavedGame
+}
+
+var savedGameStore *SavedGameStore
+
+// SavedGames returns the store of saved games, opening it if need be
+func (c ConfigT) SavedGames() *SavedGameStore {
+	if savedGameStore == nil {
+		savedGameStore = NewSavedGameStore(c.app.Storage())
+		c.migrateSavedGames(savedGameStore)
+	}
+	return savedGameStore
+}
+
+func NewSavedGameStore(st fyne.Storage) *SavedGameStore {
+	s := &SavedGameStore{storage: st, games: make(map[string]*SavedGame)}
+	index, err := s.readIndex(savedGamesIndexFile)
+	if err != nil {
+		// the backup is complete whenever the index might not be
+		index, err = s.readIndex(savedGamesIndexBackup)
+	}
+	if err == nil {
+		for _, saved := range index {
+			s.games[saved.Name] = saved
+		}
+		return s
+	}
+	if s.rebuildIndex() {
+		fyne.LogError("Unable to read the saved games index, rebuilt it from the saved games", err)
+		if err := s.writeIndex(); err != nil {
+			fyne.LogError("Unable to write the saved games index", err)
+		}
+	}
+	return s
+}
+
+func (s *SavedGameStore) readIndex(file string) ([]*SavedGame, error) {
+	reader, err := s.storage.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	var index []*SavedGame
+	if err := json.NewDecoder(reader).Decode(&index); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// rebuildIndex finds the saved games by looking for their files, for
+// when the index has been lost.  They get their names back from the
+// patterns, but not the dates they were saved.  Returns whether there
+// were any.
+func (s *SavedGameStore) rebuildIndex() bool {
+	files := s.storage.List()
+	sort.Strings(files)
+	recovered := 0
+	for _, file := range files {
+		if !strings.HasPrefix(file, "saved_game_") || !strings.HasSuffix(file, ".rle") {
+			continue
+		}
+		reader, err := s.storage.Open(file)
+		if err != nil {
+			fyne.LogError("Unable to open saved game "+file, err)
+			continue
+		}
+		pattern, err := ReadRLE(reader)
+		reader.Close()
+		if err != nil {
+			fyne.LogError("Unable to read saved game "+file, err)
+			continue
+		}
+		name := strings.TrimSpace(pattern.Game.Name)
+		for counter := 1; name == "" || s.games[name] != nil; counter++ {
+			name = fmt.Sprintf("Recovered game %d", counter)
+		}
+		pattern.Game.Name = name
+		s.games[name] = &SavedGame{Name: name, File: file, Cells: pattern.Game.Size() + len(pattern.Dying)}
+		recovered++
+	}
+	return recovered > 0
+}
+
+// Names returns the names of the games in alphabetical order
+func (s *SavedGameStore) Names() []string {
+	names := make([]string, 0, len(s.games))
+	for name := range s.games {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return strings.ToLower(names[i]) < strings.ToLower(names[j])
+	})
+	return names
+}
+
+// Get returns the game with that name, or nil if there isn't one
+func (s *SavedGameStore) Get(name string) *SavedGame {
+	return s.games[name]
+}
+
+// Load returns a copy of the pattern saved under the name, keeping it
+// in memory in case it's opened again
+func (s *SavedGameStore) Load(name string) (*Pattern, error) {
+	pattern, err := s.Peek(name)
+	if err != nil {
+		return nil, err
+	}
+	saved := s.games[name]
+	if saved.pattern == nil {
+		saved.pattern = pattern.Copy()
+	}
+	return pattern, nil
+}
+
+// Peek returns a copy of the pattern saved under the name without keeping
+// it in memory, for things like thumbnails that only need a quick look
+func (s *SavedGameStore) Peek(name string) (*Pattern, error) {
+	saved, found := s.games[name]
+	if !found {
+		return nil, fmt.Errorf("There's no saved game called %s", name)
+	}
+	if saved.pattern != nil {
+		return saved.pattern.Copy(), nil
+	}
+	reader, err := s.storage.Open(saved.File)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	pattern, err := ReadRLE(reader)
+	if err != nil {
+		return nil, err
+	}
+	pattern.Game.Name = name
+	return pattern, nil
+}
+
+// Save saves the pattern under the name, replacing any game already
+// saved with that name.
+func (s *SavedGameStore) Save(name string, pattern *Pattern) error {
+	return s.save(name, pattern, time.Now())
+}
+
+func (s *SavedGameStore) save(name string, pattern *Pattern, date time.Time) error {
+	if err := s.put(name, pattern, date); err != nil {
+		return err
+	}
+	return s.writeIndex()
+}
+
+// put writes the pattern's file and adds it to the games, without
+// updating the index
+func (s *SavedGameStore) put(name string, pattern *Pattern, date time.Time) error {
+	pattern = pattern.Copy()
+	pattern.Game.Name = name
+	saved, found := s.games[name]
+	if !found {
+		saved = &SavedGame{Name: name, File: s.newFileName()}
+	}
+	err := writeStorageFile(s.storage, saved.File, func(writer io.Writer) error {
+		return WriteRLE(pattern, writer)
+	})
+	if err != nil {
+		return err
+	}
+	// it's read back when it's opened, so saving lots of games (or
+	// moving them over from the preferences) doesn't keep them all
+	saved.Saved, saved.Cells, saved.pattern = date, pattern.Game.Size()+len(pattern.Dying), nil
+	s.games[name] = saved
+	return nil
+}
+
+// Rename gives a game a new name, as long as it isn't already taken
+func (s *SavedGameStore) Rename(oldName, newName string) error {
+	saved, found := s.games[oldName]
+	if !found {
+		return fmt.Errorf("There's no saved game called %s", oldName)
+	}
+	if _, taken := s.games[newName]; taken {
+		return fmt.Errorf("There's already a game called %s", newName)
+	}
+	delete(s.games, oldName)
+	saved.Name = newName
+	if saved.pattern != nil {
+		saved.pattern.Game.Name = newName
+	}
+	s.games[newName] = saved
+	return s.writeIndex()
+}
+
+// Duplicate saves a copy of a game under a new name
+func (s *SavedGameStore) Duplicate(name, newName string) error {
+	if _, taken := s.games[newName]; taken {
+		return fmt.Errorf("There's already a game called %s", newName)
+	}
+	pattern, err := s.Peek(name)
+	if err != nil {
+		return err
+	}
+	return s.save(newName, pattern, s.games[name].Saved)
+}
+
+func (s *SavedGameStore) Delete(name string) error {
+	saved, found := s.games[name]
+	if !found {
+		return nil
+	}
+	delete(s.games, name)
+	if err := s.writeIndex(); err != nil {
+		return err
+	}
+	return s.storage.Remove(saved.File)
+}
+
+// newFileName picks a file name that isn't being used by any game
+func (s *SavedGameStore) newFileName() string {
+	used := make(map[string]bool, len(s.games))
+	for _, saved := range s.games {
+		used[saved.File] = true
+	}
+	for _, file := range s.storage.List() {
+		used[file] = true
+	}
+	for counter := 1; ; counter++ {
+		file := fmt.Sprintf("saved_game_%d.rle", counter)
+		if !used[file] {
+			return file
+		}
+	}
+}
+
+func (s *SavedGameStore) writeIndex() error {
+	index := make([]*SavedGame, 0, len(s.games))
+	for _, name := range s.Names() {
+		index = append(index, s.games[name])
+	}
+	write := func(writer io.Writer) error {
+		return json.NewEncoder(writer).Encode(index)
+	}
+	// fyne's storage can't rename files, so a complete copy is written
+	// first and only removed once the index itself is written
+	if err := writeStorageFile(s.storage, savedGamesIndexBackup, write); err != nil {
+		return err
+	}
+	if err := writeStorageFile(s.storage, savedGamesIndexFile, write); err != nil {
+		return err
+	}
+	return s.storage.Remove(savedGamesIndexBackup)
+}
+
+// writeStorageFile replaces the file in the app's storage, creating it
+// if it's new
+func writeStorageFile(st fyne.Storage, name string, write func(io.Writer) error) error {
+	writer, err := st.Save(name)
+	if errors.Is(err, storage.ErrNotExists) {
+		writer, err = st.Create(name)
+	}
+	if err != nil {
+		return err
+	}
+	if err := write(writer); err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+// Older versions kept the date as a comment at the top of the RLE
+const savedDateComment = "C saved at "
+
+// migrateSavedGames moves the games saved in the preferences by older
+// versions into the store.  They're only removed from the preferences
+// once they've all made it.
+func (c ConfigT) migrateSavedGames(s *SavedGameStore) {
+	gameStrs := c.app.Preferences().StringList(savedGamesKey)
+	if len(gameStrs) == 0 {
+		return
+	}
+	blankCounter := 1
+	failed := false
+	for _, rleData := range gameStrs {
+		reader := strings.NewReader(rleData)
+		pattern, err := ReadRLE(reader)
+		if err != nil {
+			fyne.LogError("Unable to decode saved game", err)
+			continue
+		}
+		name := pattern.Game.Name
+		for name == "" {
+			name = fmt.Sprintf("Blank game %d", blankCounter)
+			if s.Get(name) != nil {
+				name = ""
+			}
+			blankCounter += 1
+		}
+		var date time.Time
+		comments := pattern.Game.Comments
+		if len(comments) > 0 && strings.HasPrefix(comments[0], savedDateComment) {
+			date, _ = time.Parse(time.RFC3339, strings.TrimPrefix(comments[0], savedDateComment))
+			pattern.Game.Comments = comments[1:]
+		}
+		if err := s.put(name, pattern, date); err != nil {
+			fyne.LogError(fmt.Sprintf("Unable to move saved game %s", name), err)
+			failed = true
+		}
+	}
+	if err := s.writeIndex(); err != nil {
+		fyne.LogError("Unable to write the saved games index", err)
+		failed = true
+	}
+	if !failed {
+		c.app.Preferences().RemoveValue(savedGamesKey)
+	}
+}
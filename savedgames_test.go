@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/pneumaticdeath/golife"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+)
+
+// memStorage is an app storage kept in memory, so tests don't share files
+type memStorage struct {
+	files map[string][]byte
+}
+
+func newMemStorage() *memStorage {
+	return &memStorage{files: make(map[string][]byte)}
+}
+
+type memFile struct {
+	bytes.Buffer
+	uri   fyne.URI
+	close func([]byte)
+}
+
+func (f *memFile) URI() fyne.URI {
+	return f.uri
+}
+
+func (f *memFile) Close() error {
+	if f.close != nil {
+		f.close(f.Bytes())
+	}
+	return nil
+}
+
+func (ms *memStorage) RootURI() fyne.URI {
+	return storage.NewFileURI("/memory")
+}
+
+func (ms *memStorage) uri(name string) fyne.URI {
+	return storage.NewFileURI("/memory/" + name)
+}
+
+func (ms *memStorage) writer(name string) *memFile {
+	return &memFile{uri: ms.uri(name), close: func(contents []byte) {
+		ms.files[name] = slices.Clone(contents)
+	}}
+}
+
+func (ms *memStorage) Create(name string) (fyne.URIWriteCloser, error) {
+	if _, found := ms.files[name]; found {
+		return nil, storage.ErrAlreadyExists
+	}
+	return ms.writer(name), nil
+}
+
+func (ms *memStorage) Open(name string) (fyne.URIReadCloser, error) {
+	contents, found := ms.files[name]
+	if !found {
+		return nil, storage.ErrNotExists
+	}
+	return &memFile{Buffer: *bytes.NewBuffer(contents), uri: ms.uri(name)}, nil
+}
+
+func (ms *memStorage) Save(name string) (fyne.URIWriteCloser, error) {
+	if _, found := ms.files[name]; !found {
+		return nil, storage.ErrNotExists
+	}
+	return ms.writer(name), nil
+}
+
+func (ms *memStorage) Remove(name string) error {
+	delete(ms.files, name)
+	return nil
+}
+
+func (ms *memStorage) List() []string {
+	names := make([]string, 0, len(ms.files))
+	for name := range ms.files {
+		names = append(names, name)
+	}
+	return names
+}
+
+func saveTestGames(t *testing.T, st fyne.Storage, names ...string) {
+	store := NewSavedGameStore(st)
+	for index, name := range names {
+		// each game has one more cell than the last, to tell them apart
+		pattern := newTestPattern()
+		for x := range index + 1 {
+			pattern.Game.AddCell(golife.Cell{X: golife.Coord(2 * x), Y: 0})
+		}
+		if err := store.Save(name, pattern); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSavedGameStoreReopens(t *testing.T) {
+	st := newMemStorage()
+	saveTestGames(t, st, "Beta", "alpha", "Gamma")
+	if _, found := st.files[savedGamesIndexBackup]; found {
+		t.Error("the index backup was left behind")
+	}
+	store := NewSavedGameStore(st)
+	if got, want := store.Names(), []string{"alpha", "Beta", "Gamma"}; !slices.Equal(got, want) {
+		t.Errorf("got games %v, want %v", got, want)
+	}
+	if store.Get("Beta").Saved.IsZero() {
+		t.Error("the date was lost")
+	}
+}
+
+func TestSavedGameStoreRecovery(t *testing.T) {
+	tests := []struct {
+		name   string
+		damage func(*memStorage)
+		dated  bool // whether the dates survive
+	}{
+		{"index cut short", func(st *memStorage) {
+			st.files[savedGamesIndexFile] = st.files[savedGamesIndexFile][:10]
+		}, false},
+		{"index cut short with the backup still there", func(st *memStorage) {
+			st.files[savedGamesIndexBackup] = st.files[savedGamesIndexFile]
+			st.files[savedGamesIndexFile] = nil
+		}, true},
+		{"index missing", func(st *memStorage) {
+			delete(st.files, savedGamesIndexFile)
+		}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			st := newMemStorage()
+			saveTestGames(t, st, "One", "Two", "Three")
+			test.damage(st)
+
+			store := NewSavedGameStore(st)
+			if got, want := store.Names(), []string{"One", "Three", "Two"}; !slices.Equal(got, want) {
+				t.Fatalf("got games %v, want %v", got, want)
+			}
+			if dated := !store.Get("Two").Saved.IsZero(); dated != test.dated {
+				t.Errorf("date kept is %v, want %v", dated, test.dated)
+			}
+			pattern, err := store.Load("Two")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pattern.Game.Size() != 2 {
+				t.Error("recovered the wrong pattern")
+			}
+
+			// saving another game keeps the recovered ones
+			if err := store.Save("Four", newTestPattern()); err != nil {
+				t.Fatal(err)
+			}
+			if got := len(NewSavedGameStore(st).Names()); got != 4 {
+				t.Errorf("got %d games after saving another, want 4", got)
+			}
+		})
+	}
+}
+
+func TestSavedGameStoreRecoversUnnamedGames(t *testing.T) {
+	st := newMemStorage()
+	st.files["saved_game_7.rle"] = []byte("x = 1, y = 1\no!\n")
+	st.files["saved_game_8.rle"] = []byte("not a pattern")
+	st.files["other.rle"] = []byte("#N Other\nx = 1, y = 1\no!\n")
+	store := NewSavedGameStore(st)
+	if got, want := store.Names(), []string{"Recovered game 1"}; !slices.Equal(got, want) {
+		t.Errorf("got games %v, want %v", got, want)
+	}
+	if store.Get("Recovered game 1").File != "saved_game_7.rle" {
+		t.Error("recovered game points at the wrong file")
+	}
+}
+
+func TestSavedGameStoreRename(t *testing.T) {
+	st := newMemStorage()
+	saveTestGames(t, st, "One", "Two")
+	store := NewSavedGameStore(st)
+	if err := store.Rename("One", "Two"); err == nil {
+		t.Error("renamed over another game")
+	}
+	if err := store.Rename("One", "Uno"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Duplicate("Uno", "Copy"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete("Two"); err != nil {
+		t.Fatal(err)
+	}
+	reopened := NewSavedGameStore(st)
+	if got, want := reopened.Names(), []string{"Copy", "Uno"}; !slices.Equal(got, want) {
+		t.Errorf("got games %v, want %v", got, want)
+	}
+	if !reopened.Get("Copy").Saved.Equal(reopened.Get("Uno").Saved) {
+		t.Error("the copy didn't keep the date")
+	}
+}
+
+func TestSavedGameStoreOnlyKeepsOpenedGames(t *testing.T) {
+	st := newMemStorage()
+	saveTestGames(t, st, "One", "Two")
+	store := NewSavedGameStore(st)
+	for _, name := range store.Names() {
+		if _, err := store.Peek(name); err != nil {
+			t.Fatal(err)
+		}
+		if store.Get(name).pattern != nil {
+			t.Errorf("%s is kept after a peek", name)
+		}
+	}
+	if _, err := store.Load("Two"); err != nil {
+		t.Fatal(err)
+	}
+	if store.Get("Two").pattern == nil {
+		t.Error("Two isn't kept after opening it")
+	}
+	if store.Get("One").pattern != nil {
+		t.Error("One is kept without being opened")
+	}
+	if err := store.Duplicate("Two", "Copy"); err != nil {
+		t.Fatal(err)
+	}
+	if store.Get("Copy").pattern != nil {
+		t.Error("the copy is kept without being opened")
+	}
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"errors"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/pneumaticdeath/golife"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+)
+
+// The selection tool lets the user drag out a rectangle of cells while
+// in edit mode, and then copy, cut or delete the cells inside it.  Copied
+// cells are put on the system clipboard as RLE text so they can be
+// pasted into another tab (or another Life program entirely).  Pasting
+// puts the pattern in a floating preview that follows the pointer until
+// the user taps to place it.
+
+// CellAt converts a position on the drawing surface into the coordinates
+// of the cell that is displayed there.
+func (ls *LifeSim) CellAt(pos fyne.Position) golife.Cell {
+	// Slightly non-obvious, but the upper left
+	// corner of the dislay box is not  necessarily
+	// aligned at the uppper left corner, but the
+	// center of the display box is always the same
+	// as the center of the window
+	windowSize := ls.drawingSurface.Size()
+	windowCenter_x := windowSize.Width / 2.0
+	windowCenter_y := windowSize.Height / 2.0
+	boxCenter_x := (ls.BoxDisplayMax.X + ls.BoxDisplayMin.X) / 2.0
+	boxCenter_y := (ls.BoxDisplayMax.Y + ls.BoxDisplayMin.Y) / 2.0
+	x, y := pos.Components()
+	cell_x := golife.Coord(math.Floor(float64((x-windowCenter_x)/ls.Scale + boxCenter_x + 0.5)))
+	cell_y := golife.Coord(math.Floor(float64((y-windowCenter_y)/ls.Scale + boxCenter_y + 0.5)))
+	return golife.Cell{X: cell_x, Y: cell_y}
+}
+
+// windowPos is the inverse of CellAt, returning the upper left corner
+// of where the given cell is drawn on the drawing surface.
+func (ls *LifeSim) windowPos(cell golife.Cell) fyne.Position {
+	windowSize := ls.drawingSurface.Size()
+	displayCenter_x := (ls.BoxDisplayMax.X + ls.BoxDisplayMin.X) / 2.0
+	displayCenter_y := (ls.BoxDisplayMax.Y + ls.BoxDisplayMin.Y) / 2.0
+	window_x := windowSize.Width/2.0 + ls.Scale*(float32(cell.X)-displayCenter_x) - ls.Scale/2.0
+	window_y := windowSize.Height/2.0 + ls.Scale*(float32(cell.Y)-displayCenter_y) - ls.Scale/2.0
+	return fyne.NewPos(window_x, window_y)
+}
+
+// HasSelection reports whether there is currently a selected rectangle.
+func (ls *LifeSim) HasSelection() bool {
+	return ls.selectionActive
+}
+
+// Selection returns the upper left and lower right corners (inclusive)
+// of the current selection.  The final value is false if there is no
+// selection.
+func (ls *LifeSim) Selection() (golife.Cell, golife.Cell, bool) {
+	if !ls.selectionActive {
+		return golife.Cell{}, golife.Cell{}, false
+	}
+	minCell := golife.Cell{X: min(ls.selectionStart.X, ls.selectionEnd.X), Y: min(ls.selectionStart.Y, ls.selectionEnd.Y)}
+	maxCell := golife.Cell{X: max(ls.selectionStart.X, ls.selectionEnd.X), Y: max(ls.selectionStart.Y, ls.selectionEnd.Y)}
+	return minCell, maxCell, true
+}
+
+// SetSelection selects the rectangle with the given (inclusive) corners.
+func (ls *LifeSim) SetSelection(corner1, corner2 golife.Cell) {
+	ls.selectionStart, ls.selectionEnd = corner1, corner2
+	ls.selectionActive = true
+	ls.Dirty = true
+}
+
+func (ls *LifeSim) ClearSelection() {
+	ls.selectionActive = false
+	ls.dragSelecting = false
+	ls.Dirty = true
+}
+
+func inBox(cell, minCell, maxCell golife.Cell) bool {
+	return cell.X >= minCell.X && cell.X <= maxCell.X && cell.Y >= minCell.Y && cell.Y <= maxCell.Y
+}
+
+// SelectedCells returns a copy of the live cells inside the selection.
+func (ls *LifeSim) SelectedCells() golife.Population {
+	ls.SyncPopulation()
+	selected := make(golife.Population)
+	minCell, maxCell, ok := ls.Selection()
+	if !ok {
+		return selected
+	}
+	for cell := range ls.Game.Population {
+		if inBox(cell, minCell, maxCell) {
+			selected[cell] = true
+		}
+	}
+	return selected
+}
+
+// DeleteSelection removes every live cell inside the selection.
+func (ls *LifeSim) DeleteSelection() {
+	ls.SyncPopulation()
+	removed := make([]golife.Cell, 0, 16)
+	for cell := range ls.SelectedCells() {
+		ls.Game.RemoveCell(cell)
+		removed = append(removed, cell)
+	}
+	ls.RecordEdit(Edit{Removed: removed})
+	ls.Dirty = true
+}
+
+// IsPasting reports whether a pattern is waiting to be placed.
+func (ls *LifeSim) IsPasting() bool {
+	return ls.pasteBuffer != nil
+}
+
+// StartPaste puts the given cells into the floating paste preview.  The
+// preview follows the pointer until it is placed with PlacePaste (a tap)
+// or discarded with CancelPaste.
+func (ls *LifeSim) StartPaste(pop golife.Population) {
+	ls.pasteBuffer = normalizePopulation(pop)
+	ls.stamping = false
+	_, size := ls.pasteBuffer.BoundingBox()
+	boxCenter := golife.Cell{X: golife.Coord((ls.BoxDisplayMin.X + ls.BoxDisplayMax.X) / 2.0),
+		Y: golife.Coord((ls.BoxDisplayMin.Y + ls.BoxDisplayMax.Y) / 2.0)}
+	ls.pasteAt = golife.Cell{X: boxCenter.X - size.X/2, Y: boxCenter.Y - size.Y/2}
+	ls.Dirty = true
+}
+
+// PlacePaste adds the paste preview to the game at its current location
+// and selects the newly placed cells.  A stamp isn't selected, and stays
+// ready to be placed again.
+func (ls *LifeSim) PlacePaste() {
+	if ls.pasteBuffer == nil {
+		return
+	}
+	ls.SyncPopulation()
+	_, size := ls.pasteBuffer.BoundingBox()
+	added := make([]golife.Cell, 0, len(ls.pasteBuffer))
+	for cell := range ls.pasteBuffer {
+		newCell := golife.Cell{X: cell.X + ls.pasteAt.X, Y: cell.Y + ls.pasteAt.Y}
+		if !ls.Game.HasCell(newCell) {
+			ls.Game.AddCell(newCell)
+			added = append(added, newCell)
+		}
+	}
+	ls.RecordEdit(Edit{Added: added})
+	ls.Dirty = true
+	if ls.stamping {
+		return
+	}
+	ls.SetSelection(ls.pasteAt, golife.Cell{X: ls.pasteAt.X + size.X, Y: ls.pasteAt.Y + size.Y})
+	ls.pasteBuffer = nil
+}
+
+func (ls *LifeSim) CancelPaste() {
+	ls.pasteBuffer = nil
+	ls.stamping = false
+	ls.Dirty = true
+}
+
+// movePasteTo centers the paste preview on the cell under the pointer.
+func (ls *LifeSim) movePasteTo(pos fyne.Position) {
+	_, size := ls.pasteBuffer.BoundingBox()
+	cell := ls.CellAt(pos)
+	newAt := golife.Cell{X: cell.X - size.X/2, Y: cell.Y - size.Y/2}
+	if newAt != ls.pasteAt {
+		ls.pasteAt = newAt
+		ls.Dirty = true
+	}
+}
+
+func (ls *LifeSim) MouseIn(e *desktop.MouseEvent) {
+	ls.MouseMoved(e)
+}
+
+func (ls *LifeSim) MouseMoved(e *desktop.MouseEvent) {
+	if ls.IsPasting() {
+		ls.movePasteTo(e.Position)
+	}
+}
+
+func (ls *LifeSim) MouseOut() {
+	// Nothing to do here, the paste preview stays where it was last seen
+}
+
+// dragSelect extends the selection rectangle to follow an edit-mode drag.
+func (ls *LifeSim) dragSelect(e *fyne.DragEvent) {
+	if !ls.dragSelecting {
+		ls.dragSelecting = true
+		ls.SetSelection(ls.CellAt(e.Position.Subtract(e.Dragged)), ls.CellAt(e.Position))
+		return
+	}
+	endCell := ls.CellAt(e.Position)
+	if endCell != ls.selectionEnd {
+		ls.selectionEnd = endCell
+		ls.Dirty = true
+	}
+}
+
+// normalizePopulation returns a copy of the population translated so the
+// upper left corner of its bounding box is at 0,0.
+func normalizePopulation(pop golife.Population) golife.Population {
+	minCell, _ := pop.BoundingBox()
+	normalized := make(golife.Population, len(pop))
+	for cell := range pop {
+		normalized[golife.Cell{X: cell.X - minCell.X, Y: cell.Y - minCell.Y}] = true
+	}
+	return normalized
+}
+
+// PopulationToRLE encodes the cells as RLE text suitable for the clipboard.
+func PopulationToRLE(pop golife.Population, rule Rule) (string, error) {
+	if len(pop) == 0 {
+		return "", errors.New("No cells selected")
+	}
+	game := golife.NewGame()
+	for cell := range pop {
+		game.AddCell(cell)
+	}
+	var writer strings.Builder
+	err := WriteRLE(&Pattern{Game: game, Rule: rule}, &writer)
+	if err != nil {
+		return "", err
+	}
+	return writer.String(), nil
+}
+
+// PopulationFromRLE decodes clipboard text back into cells.
+func PopulationFromRLE(text string) (golife.Population, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, errors.New("Clipboard is empty")
+	}
+	pattern, err := ReadRLE(strings.NewReader(text))
+	if err != nil {
+		return nil, err
+	}
+	game := pattern.Game
+	if game.Size() == 0 {
+		return nil, errors.New("Clipboard pattern has no live cells")
+	}
+	return game.Population, nil
+}
+
+// CopySelection puts the selected cells on the clipboard as RLE.
+func (ls *LifeSim) CopySelection(clipboard fyne.Clipboard) error {
+	rle, err := PopulationToRLE(ls.SelectedCells(), ls.Rule)
+	if err != nil {
+		return err
+	}
+	clipboard.SetContent(rle)
+	return nil
+}
+
+// CutSelection copies the selected cells to the clipboard and then removes them.
+func (ls *LifeSim) CutSelection(clipboard fyne.Clipboard) error {
+	err := ls.CopySelection(clipboard)
+	if err != nil {
+		return err
+	}
+	ls.DeleteSelection()
+	return nil
+}
+
+// PasteFromClipboard starts a paste of the RLE pattern on the clipboard.
+func (ls *LifeSim) PasteFromClipboard(clipboard fyne.Clipboard) error {
+	pop, err := PopulationFromRLE(clipboard.Content())
+	if err != nil {
+		return err
+	}
+	ls.StartPaste(pop)
+	return nil
+}
+
+func withAlpha(clr color.Color, alpha uint8) color.Color {
+	nrgba := color.NRGBAModel.Convert(clr).(color.NRGBA)
+	nrgba.A = alpha
+	return nrgba
+}
+
+// selectionOverlay returns the canvas objects that outline the selection
+// and the paste preview so they can be drawn on top of the cells.  It
+// must be called from the main goroutine.
+func (ls *LifeSim) selectionOverlay() []fyne.CanvasObject {
+	overlay := make([]fyne.CanvasObject, 0, 2)
+	if minCell, maxCell, ok := ls.Selection(); ok {
+		ls.selectionRect.StrokeColor = theme.Color(theme.ColorNamePrimary)
+		ls.selectionRect.Move(ls.windowPos(minCell))
+		ls.selectionRect.Resize(fyne.NewSize(ls.Scale*float32(maxCell.X-minCell.X+1), ls.Scale*float32(maxCell.Y-minCell.Y+1)))
+		overlay = append(overlay, ls.selectionRect)
+	}
+	if ls.pasteBuffer != nil {
+		_, size := ls.pasteBuffer.BoundingBox()
+		ls.pasteRect.StrokeColor = withAlpha(theme.Color(theme.ColorNamePrimary), 128)
+		ls.pasteRect.Move(ls.windowPos(ls.pasteAt))
+		ls.pasteRect.Resize(fyne.NewSize(ls.Scale*float32(size.X+1), ls.Scale*float32(size.Y+1)))
+		overlay = append(overlay, ls.pasteRect)
+	}
+	return overlay
+}
+
+func newOverlayRect() *canvas.Rectangle {
+	rect := canvas.NewRectangle(color.Transparent)
+	rect.StrokeWidth = 2
+	return rect
+}
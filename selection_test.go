@@ -0,0 +1,51 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/pneumaticdeath/golife"
+
+	"fyne.io/fyne/v2/test"
+)
+
+// a blinker that's vertical at even generations
+var testBlinker = []golife.Cell{{X: 1, Y: 0}, {X: 1, Y: 1}, {X: 1, Y: 2}}
+
+func newHashLifeTestSim(cells []golife.Cell) *LifeSim {
+	InitConfig(test.NewApp())
+	sim := NewLifeSim(func() {})
+	sim.Game.AddCells(cells)
+	sim.SetHashLife(true)
+	sim.StepLog = 0
+	sim.Step() // the population map is now a generation behind
+	return sim
+}
+
+func TestSelectionEditsAfterHashLifeStep(t *testing.T) {
+	horizontal := []golife.Cell{{X: 0, Y: 1}, {X: 1, Y: 1}, {X: 2, Y: 1}}
+
+	sim := newHashLifeTestSim(testBlinker)
+	sim.SetSelection(golife.Cell{X: 0, Y: 0}, golife.Cell{X: 2, Y: 2})
+	if got := sortedCells(populationCells(sim.SelectedCells())); !slices.Equal(got, horizontal) {
+		t.Errorf("copied %v, want the current generation %v", got, horizontal)
+	}
+
+	sim = newHashLifeTestSim(testBlinker)
+	sim.SetSelection(golife.Cell{X: 0, Y: 0}, golife.Cell{X: 2, Y: 2})
+	sim.DeleteSelection()
+	if sim.CellCount() != 0 {
+		t.Errorf("%d cells left after deleting them all", sim.CellCount())
+	}
+
+	sim = newHashLifeTestSim(testBlinker)
+	sim.StartPaste(golife.Population{{X: 0, Y: 0}: true})
+	sim.pasteAt = golife.Cell{X: 10, Y: 10}
+	sim.PlacePaste()
+	if got, want := sim.CellCount(), 4; got != want {
+		t.Errorf("got %d cells after pasting, want %d", got, want)
+	}
+	if !sim.Game.HasCell(golife.Cell{X: 0, Y: 1}) {
+		t.Error("pasting went into an old generation")
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/pneumaticdeath/golife"
+
+	"fyne.io/fyne/v2"
+)
+
+// Session restore saves every open tab when the app quits, and opens
+// them all again the next time it starts.  Each tab is kept as its
+// pattern in RLE plus the things RLE doesn't know about, like where the
+// pattern sits on the board and how the tab was being viewed.  The tabs
+// go in a file in the app's storage, like the saved games, since big
+// patterns don't belong in the preferences.  Older versions kept them
+// there, and they're read from there if there's no file yet.
+
+const sessionFile = "session.json"
+
+type SessionTab struct {
+	Pattern     string       // the pattern in RLE, which loses its position
+	X, Y        golife.Coord // where the upper left corner of the pattern goes
+	Generation  int
+	Filename    string
+	Comments    []string
+	Selected    bool
+	ViewMin     fyne.Position
+	ViewMax     fyne.Position
+	AutoZoom    bool
+	GlyphStyle  string
+	Speed       float64
+	KeepRunning bool
+}
+
+func NewSessionTab(lc *LifeContainer, selected bool) (SessionTab, error) {
+	pattern := lc.Sim.Pattern().Copy()
+	game := pattern.Game
+	st := SessionTab{
+		Generation:  game.Generation,
+		Filename:    game.Filename,
+		Comments:    game.Comments,
+		Selected:    selected,
+		ViewMin:     lc.Sim.BoxDisplayMin,
+		ViewMax:     lc.Sim.BoxDisplayMax,
+		AutoZoom:    lc.Sim.IsAutoZoom(),
+		GlyphStyle:  lc.Sim.GlyphStyle,
+		Speed:       lc.Control.speedSlider.Value,
+		KeepRunning: lc.Control.KeepRunning(),
+	}
+	if game.Size() > 0 || len(pattern.Dying) > 0 {
+		minCell, _ := PatternBoundingBox(pattern)
+		st.X, st.Y = minCell.X, minCell.Y
+	}
+
+	// these are kept separately so they don't pile up as comments
+	game.Filename, game.Generation, game.Comments = "", 0, nil
+	var writer strings.Builder
+	if err := WriteRLE(pattern, &writer); err != nil {
+		return st, err
+	}
+	st.Pattern = writer.String()
+	return st, nil
+}
+
+// Restore puts the saved tab into the container
+func (st SessionTab) Restore(lc *LifeContainer) error {
+	saved, err := ReadRLE(strings.NewReader(st.Pattern))
+	if err != nil {
+		return err
+	}
+	pattern := &Pattern{Game: golife.NewGame(), Rule: saved.Rule}
+	game := pattern.Game
+	game.Name, game.Author = saved.Game.Name, saved.Game.Author
+	game.Filename, game.Generation, game.Comments = st.Filename, st.Generation, st.Comments
+	for cell := range saved.Game.Population {
+		game.AddCell(golife.Cell{X: cell.X + st.X, Y: cell.Y + st.Y})
+	}
+	if len(saved.Dying) > 0 {
+		pattern.Dying = make(DyingCells, len(saved.Dying))
+		for cell, state := range saved.Dying {
+			pattern.Dying[golife.Cell{X: cell.X + st.X, Y: cell.Y + st.Y}] = state
+		}
+	}
+
+	lc.replacePattern(pattern)
+	lc.Sim.EditMode.Set(game.Size() == 0)
+	if st.GlyphStyle != "" {
+		lc.Control.glyphSelector.SetSelected(st.GlyphStyle)
+	}
+	if st.Speed != 0 {
+		lc.Control.speedSlider.SetValue(st.Speed)
+	}
+	if st.AutoZoom != lc.Sim.IsAutoZoom() {
+		lc.Sim.SetAutoZoom(st.AutoZoom)
+	}
+	lc.Sim.SetDisplayBox(st.ViewMin, st.ViewMax)
+	lc.Control.SetKeepRunning(st.KeepRunning)
+	return nil
+}
+
+// SaveSession remembers the tabs for next time, or forgets any session
+// that was saved before if session restore is turned off.
+func SaveSession(containers []*LifeContainer, selected int) {
+	if !Config.RestoreSession() {
+		Config.SetSessionTabs(nil)
+		return
+	}
+	session := make([]SessionTab, 0, len(containers))
+	for index, lc := range containers {
+		lc.Control.StopSim()
+		st, err := NewSessionTab(lc, index == selected)
+		if err != nil {
+			fyne.LogError("Unable to save tab to the session", err)
+			continue
+		}
+		session = append(session, st)
+	}
+	Config.SetSessionTabs(session)
+}
+
+// RestoreSession reopens the tabs from the last session.  The first one
+// goes in the tab that's already open, and newLC makes the rest.
+func RestoreSession(tabs *LifeTabs, newLC func() *LifeContainer) {
+	if !Config.RestoreSession() {
+		return
+	}
+	session := Config.SessionTabs()
+	if len(session) == 0 {
+		return
+	}
+	selected := 0
+	for index, st := range session {
+		lc := tabs.CurrentLifeContainer()
+		if index > 0 {
+			lc = newLC()
+			tabs.NewTab(lc)
+		}
+		if err := st.Restore(lc); err != nil {
+			fyne.LogError("Unable to restore tab from the session", err)
+		}
+		tabs.UpdateTitle()
+		if st.Selected {
+			selected = index
+		}
+	}
+	tabs.DocTabs.SelectIndex(selected)
+}
+
+func (c ConfigT) SessionTabs() []SessionTab {
+	session, err := readSessionTabs(c.app.Storage())
+	if err == nil {
+		return session
+	}
+	tabStrs := c.app.Preferences().StringList(sessionTabsKey)
+	session = make([]SessionTab, 0, len(tabStrs))
+	for _, tabStr := range tabStrs {
+		var st SessionTab
+		if err := json.Unmarshal([]byte(tabStr), &st); err != nil {
+			fyne.LogError("Unable to decode session tab", err)
+			continue
+		}
+		session = append(session, st)
+	}
+	return session
+}
+
+func (c ConfigT) SetSessionTabs(session []SessionTab) {
+	if err := writeSessionTabs(c.app.Storage(), session); err != nil {
+		fyne.LogError("Unable to save the session", err)
+		return
+	}
+	c.app.Preferences().RemoveValue(sessionTabsKey)
+}
+
+func readSessionTabs(st fyne.Storage) ([]SessionTab, error) {
+	reader, err := st.Open(sessionFile)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	var session []SessionTab
+	if err := json.NewDecoder(reader).Decode(&session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// writeSessionTabs saves the session, or removes the saved one if it's empty
+func writeSessionTabs(st fyne.Storage, session []SessionTab) error {
+	if len(session) == 0 {
+		if !slices.Contains(st.List(), sessionFile) {
+			return nil
+		}
+		return st.Remove(sessionFile)
+	}
+	return writeStorageFile(st, sessionFile, func(writer io.Writer) error {
+		return json.NewEncoder(writer).Encode(session)
+	})
+}
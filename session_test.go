@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/pneumaticdeath/golife"
+
+	"fyne.io/fyne/v2/test"
+)
+
+func TestSessionTabsFile(t *testing.T) {
+	InitConfig(test.NewApp())
+	lc := NewLifeContainer(func() {})
+	pattern := newTestPattern(golife.Cell{X: 5, Y: -3}, golife.Cell{X: 6, Y: -3})
+	pattern.Game.Generation = 42
+	lc.SetPattern(pattern)
+	tab, err := NewSessionTab(lc, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	st := newMemStorage()
+	if err := writeSessionTabs(st, []SessionTab{tab, tab}); err != nil {
+		t.Fatal(err)
+	}
+	session, err := readSessionTabs(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(session) != 2 {
+		t.Fatalf("got %d tabs back, want 2", len(session))
+	}
+
+	restored := NewLifeContainer(func() {})
+	if err := session[0].Restore(restored); err != nil {
+		t.Fatal(err)
+	}
+	if restored.Sim.Game.Generation != 42 || !restored.Sim.Game.HasCell(golife.Cell{X: 5, Y: -3}) {
+		t.Errorf("restored generation %d with cells %v", restored.Sim.Game.Generation, populationCells(restored.Sim.Game.Population))
+	}
+
+	if err := writeSessionTabs(st, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readSessionTabs(st); err == nil {
+		t.Error("the session is still there after saving an empty one")
+	}
+	if err := writeSessionTabs(st, nil); err != nil {
+		t.Errorf("saving an empty session twice failed: %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"math"
+
+	"github.com/pneumaticdeath/golife"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+)
+
+// The shape tools draw lines, rectangles and ellipses in edit mode.  The
+// shape follows the drag as a preview (drawn like a paste preview) and
+// its cells are painted when the drag ends, as a single edit.  Filled
+// rectangles are previewed as their outline and only filled in at the
+// end, and not at all if they'd take more than maxFillCells.  Holding
+// shift keeps lines to multiples of 45 degrees and makes rectangles and
+// ellipses square and round.  The 45° line tool always does, for touch
+// devices that have no shift key.
+
+const (
+	editToolLine            = "Line"
+	editToolDiagonal        = "45° Line"
+	editToolRectangle       = "Rectangle"
+	editToolFilledRectangle = "Filled Rectangle"
+	editToolEllipse         = "Ellipse"
+)
+
+func isShapeTool(tool string) bool {
+	switch tool {
+	case editToolLine, editToolDiagonal, editToolRectangle, editToolFilledRectangle, editToolEllipse:
+		return true
+	}
+	return false
+}
+
+// snapShape returns where the shape dragged out from one cell to another
+// ends.  If constrain is set, lines are kept to 45 degrees and boxes to
+// squares, and the 45° line tool always is.
+func snapShape(tool string, from, to golife.Cell, constrain bool) golife.Cell {
+	switch {
+	case tool == editToolDiagonal || constrain && tool == editToolLine:
+		return snapLine(from, to)
+	case constrain:
+		return snapSquare(from, to)
+	}
+	return to
+}
+
+// shapeCells returns the cells of the shape dragged out from one cell to
+// another with the tool, constrained as snapShape says.  Filled
+// rectangles are returned as their outline, see fillShape.
+func shapeCells(tool string, from, to golife.Cell, constrain bool) golife.Population {
+	to = snapShape(tool, from, to, constrain)
+	cells := make(golife.Population)
+	switch tool {
+	case editToolLine, editToolDiagonal:
+		for _, cell := range cellsOnLine(from, to) {
+			cells[cell] = true
+		}
+	case editToolRectangle, editToolFilledRectangle:
+		minCell, maxCell := boxCorners(from, to)
+		for x := minCell.X; x <= maxCell.X; x++ {
+			for y := minCell.Y; y <= maxCell.Y; y++ {
+				if x == minCell.X || x == maxCell.X || y == minCell.Y || y == maxCell.Y {
+					cells[golife.Cell{X: x, Y: y}] = true
+				}
+			}
+		}
+	case editToolEllipse:
+		minCell, maxCell := boxCorners(from, to)
+		addEllipse(cells, minCell, maxCell)
+	}
+	return cells
+}
+
+// fillShape returns every cell in the rectangle with the given corners,
+// or an error if there are too many of them
+func fillShape(corner1, corner2 golife.Cell) (golife.Population, error) {
+	minCell, maxCell := boxCorners(corner1, corner2)
+	width, height := int64(maxCell.X-minCell.X)+1, int64(maxCell.Y-minCell.Y)+1
+	if err := checkFillSize(width, height); err != nil {
+		return nil, err
+	}
+	cells := make(golife.Population, width*height)
+	for x := minCell.X; x <= maxCell.X; x++ {
+		for y := minCell.Y; y <= maxCell.Y; y++ {
+			cells[golife.Cell{X: x, Y: y}] = true
+		}
+	}
+	return cells, nil
+}
+
+func boxCorners(corner1, corner2 golife.Cell) (golife.Cell, golife.Cell) {
+	return golife.Cell{X: min(corner1.X, corner2.X), Y: min(corner1.Y, corner2.Y)},
+		golife.Cell{X: max(corner1.X, corner2.X), Y: max(corner1.Y, corner2.Y)}
+}
+
+// snapLine moves the end of a line so it's horizontal, vertical or
+// diagonal, whichever is closest.
+func snapLine(from, to golife.Cell) golife.Cell {
+	dx, dy := to.X-from.X, to.Y-from.Y
+	switch {
+	case abs(dy)*2 < abs(dx):
+		return golife.Cell{X: to.X, Y: from.Y}
+	case abs(dx)*2 < abs(dy):
+		return golife.Cell{X: from.X, Y: to.Y}
+	}
+	return snapSquare(from, to)
+}
+
+// snapSquare moves the second corner so the box is square, as big as
+// the longer side.
+func snapSquare(from, to golife.Cell) golife.Cell {
+	dx, dy := to.X-from.X, to.Y-from.Y
+	side := max(abs(dx), abs(dy))
+	if dx < 0 {
+		dx = -side
+	} else {
+		dx = side
+	}
+	if dy < 0 {
+		dy = -side
+	} else {
+		dy = side
+	}
+	return golife.Cell{X: from.X + dx, Y: from.Y + dy}
+}
+
+// addEllipse adds the outline of the ellipse that fits in the box.  It's
+// worked out both column by column and row by row so the steep parts
+// don't leave gaps.
+func addEllipse(cells golife.Population, minCell, maxCell golife.Cell) {
+	centerX := float64(minCell.X+maxCell.X) / 2
+	centerY := float64(minCell.Y+maxCell.Y) / 2
+	radiusX := float64(maxCell.X-minCell.X) / 2
+	radiusY := float64(maxCell.Y-minCell.Y) / 2
+	// span is how far either side of the center the ellipse reaches at
+	// offset along the other axis
+	span := func(offset, radius, otherRadius float64) float64 {
+		if radius == 0 {
+			return 0
+		}
+		return otherRadius * math.Sqrt(max(0, 1-(offset*offset)/(radius*radius)))
+	}
+	for x := minCell.X; x <= maxCell.X; x++ {
+		dy := span(float64(x)-centerX, radiusX, radiusY)
+		cells[golife.Cell{X: x, Y: golife.Coord(math.Round(centerY - dy))}] = true
+		cells[golife.Cell{X: x, Y: golife.Coord(math.Round(centerY + dy))}] = true
+	}
+	for y := minCell.Y; y <= maxCell.Y; y++ {
+		dx := span(float64(y)-centerY, radiusY, radiusX)
+		cells[golife.Cell{X: golife.Coord(math.Round(centerX - dx)), Y: y}] = true
+		cells[golife.Cell{X: golife.Coord(math.Round(centerX + dx)), Y: y}] = true
+	}
+}
+
+// dragShape updates the preview of the shape to follow the drag
+func (ls *LifeSim) dragShape(e *fyne.DragEvent) {
+	if ls.shapePreview == nil {
+		ls.shapeStart = ls.CellAt(e.Position.Subtract(e.Dragged))
+	}
+	ls.shapeEnd = snapShape(ls.EditTool, ls.shapeStart, ls.CellAt(e.Position), shiftHeld())
+	ls.shapePreview = shapeCells(ls.EditTool, ls.shapeStart, ls.shapeEnd, false)
+	ls.Dirty = true
+}
+
+// placeShape paints the cells of the shape when the drag ends
+func (ls *LifeSim) placeShape() {
+	if ls.shapePreview == nil {
+		return
+	}
+	shape := ls.shapePreview
+	ls.shapePreview = nil
+	ls.Dirty = true
+	if ls.EditTool == editToolFilledRectangle {
+		var err error
+		if shape, err = fillShape(ls.shapeStart, ls.shapeEnd); err != nil {
+			dialog.ShowError(err, mainWindow)
+			return
+		}
+	}
+	state := ls.paintingState()
+	single := []golife.Cell{{X: 0, Y: 0}}
+	ls.startStroke(ls.shapeStart)
+	for cell := range shape {
+		ls.paintBrush(cell, state, single)
+	}
+	ls.endStroke()
+}
+
+// forEachPreview calls fn for each cell of the paste preview and of the
+// shape being dragged out, in game coordinates.
+func (ls *LifeSim) forEachPreview(fn func(cell golife.Cell)) {
+	pasteBuffer, pasteAt := ls.pasteBuffer, ls.pasteAt
+	for cell := range pasteBuffer {
+		fn(golife.Cell{X: cell.X + pasteAt.X, Y: cell.Y + pasteAt.Y})
+	}
+	for cell := range ls.shapePreview {
+		fn(cell)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/pneumaticdeath/golife"
+)
+
+func TestShapeCells(t *testing.T) {
+	from, to := golife.Cell{X: 0, Y: 0}, golife.Cell{X: 4, Y: 2}
+	tests := []struct {
+		tool      string
+		constrain bool
+		want      int
+	}{
+		{editToolLine, false, 5},
+		{editToolDiagonal, false, 5},
+		{editToolRectangle, false, 12},
+		{editToolRectangle, true, 16},
+		{editToolFilledRectangle, false, 12}, // previewed as the outline
+	}
+	for _, test := range tests {
+		if got := len(shapeCells(test.tool, from, to, test.constrain)); got != test.want {
+			t.Errorf("%s (constrained %v): got %d cells, want %d", test.tool, test.constrain, got, test.want)
+		}
+	}
+}
+
+func TestSnapShape(t *testing.T) {
+	from, to := golife.Cell{X: 0, Y: 0}, golife.Cell{X: 4, Y: 2}
+	tests := []struct {
+		tool      string
+		constrain bool
+		want      golife.Cell
+	}{
+		{editToolLine, false, to},
+		{editToolDiagonal, false, snapLine(from, to)},
+		{editToolRectangle, false, to},
+		{editToolFilledRectangle, true, snapSquare(from, to)},
+	}
+	for _, test := range tests {
+		if got := snapShape(test.tool, from, to, test.constrain); got != test.want {
+			t.Errorf("%s (constrained %v): got %v, want %v", test.tool, test.constrain, got, test.want)
+		}
+	}
+}
+
+func TestFillShape(t *testing.T) {
+	filled, err := fillShape(golife.Cell{X: 3, Y: -1}, golife.Cell{X: -2, Y: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filled) != 36 || !filled[golife.Cell{X: 0, Y: 2}] {
+		t.Errorf("got %d cells, want all 36 in the box", len(filled))
+	}
+
+	// never built, so it doesn't matter how big it is
+	if _, err := fillShape(golife.Cell{X: 0, Y: 0}, golife.Cell{X: 1 << 40, Y: 1 << 40}); err == nil {
+		t.Error("filled more than the limit")
+	}
+}
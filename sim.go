@@ -38,9 +38,21 @@ type LifeSim struct {
 	widget.BaseWidget
 
 	Game                         *golife.Game        // The underlying GameOfLife engine
+	Rule                         Rule                // The rule the game is run under
+	Dying                        DyingCells          // Cells in the dying states of a Generations rule
+	dyingHistory                 []DyingCells        // Dying cells matching each generation in Game.History
+	PaintState                   uint8               // The state a tap paints in edit mode (1 is alive)
+	hashLife                     *HashLife           // The HashLife engine, nil when using the standard engine
+	treeAhead                    bool                // HashLife has stepped past the population in Game
+	StepLog                      int                 // With HashLife, each step moves on 2^StepLog generations
+	Detector                     *PeriodDetector     // Notices when the pattern starts repeating
+	justSettled                  atomic.Bool         // The detector found a repeat since the last check
 	BoxDisplayMin, BoxDisplayMax fyne.Position       // The viewport into the game in the coordinates of the sim
 	Scale                        float32             // points per cell
 	LastStepTime                 time.Duration       // Statistic of time taken to calculate the last generation
+	Stats                        *StatsHistory       // Where every step's stats are recorded, nil if they aren't wanted
+	statsSize                    golife.Cell         // The size of the bounding box when the stats last measured it
+	statsMeasured                time.Time           // When the stats last measured the bounding box
 	LastDrawTime                 time.Duration       // How long it to draw the last frame
 	drawingSurface               *fyne.Container     // The actual drawing surface
 	State                        binding.Int         // State the game is in.
@@ -48,13 +60,16 @@ type LifeSim struct {
 	GlyphStyle                   string              // One of "Rectange", "RoundedRectangle" or "Circle"
 	autoZoom                     binding.Bool        // Should the viewport automatically expand (but never contract) to fit the full population
 	EditMode                     binding.Bool        // Whether the sim is in editable mode
+	Edits                        *EditHistory        // Undo/redo history of changes made in edit mode
+	menuUpdate                   func()              // Updates the menus when what they can do changes
 	drawLock                     sync.Mutex          // Make sure only one goroutine is drawing at any given time
 	Dirty                        bool                // Does the screen need to be redrawn
 	raster                       *canvas.Raster      // single persistent raster for zoomed-out rendering
-	screenCells                  []bool              // flat bool array indexed by py*screenCols+px
+	screenCells                  []uint8             // flat pixel array indexed by py*screenCols+px
 	screenCols                   int                 // logical width of screenCells grid
 	screenRows                   int                 // logical height of screenCells grid
-	rasterCellColor              color.Color         // cell color read by raster pixel function
+	rasterStateColors            []color.Color       // cell colors by state read by raster pixel function
+	rasterPreviewColor           color.Color         // paste preview color read by raster pixel function
 	rasterBgColor                color.Color         // background color read by raster pixel function
 	usingRaster                  bool                // tracks which path was used last frame
 	background                   *canvas.Rectangle   // reusable background rectangle for glyph path
@@ -65,7 +80,39 @@ type LifeSim struct {
 	pinchPos                     [2]fyne.Position    // last known position of each finger
 	pinchDist                    float32             // last distance between two fingers
 	pinchActive                  bool                // true when pinch gesture is in progress
-}
+	selectionActive              bool                // whether a selection rectangle exists
+	selectionStart, selectionEnd golife.Cell         // opposite corners of the selection in game coordinates
+	dragSelecting                bool                // true while an edit mode drag is sizing the selection
+	pasteBuffer                  golife.Population   // pattern waiting to be placed, normalized to 0,0
+	pasteAt                      golife.Cell         // where the upper left corner of the paste buffer would land
+	selectionRect                *canvas.Rectangle   // overlay outlining the selection
+	pasteRect                    *canvas.Rectangle   // overlay outlining the paste preview
+	previewPool                  []fyne.CanvasObject // reusable pool of paste preview glyphs
+	rasterDiffColor              color.Color         // differing cell color read by raster pixel function
+	Compare                      *LifeSim            // The sim shown beside this one to compare with, nil when not comparing
+	leader                       *LifeSim            // When this sim is the comparison, the sim it follows
+	diffAgainst                  *LifeSim            // The sim to highlight differences from, nil when not highlighting
+	EditTool                     string              // What a drag does in edit mode: select, draw or erase
+	strokeBefore                 CellStates          // What the cells painted by the current brush stroke were before it
+	strokeLast                   golife.Cell         // Where the current brush stroke has got to
+	shapeStart                   golife.Cell         // Where the shape being dragged out started
+	shapeEnd                     golife.Cell         // Where the shape being dragged out ends, once it's been snapped
+	shapePreview                 golife.Population   // The cells of the shape being dragged out, nil when there isn't one
+	stamping                     bool                // Whether the paste preview is a stamp, which stays after it's placed
+	lineage                      int64               // Changes whenever the cells change other than by stepping, so bookmarks can tell if they still apply
+	rasterGridColor              color.Color         // grid line color read by raster pixel function
+	grid                         gridCanvas          // reusable grid lines and rulers
+}
+
+// values stored in screenCells for the raster path.  Cells are stored
+// as their state (1 for live cells, 2 and up for dying cells)
+const (
+	pixelEmpty   = uint8(0)
+	pixelCell    = uint8(1)
+	pixelGrid    = uint8(253) // a major grid line between cells
+	pixelDiff    = uint8(254) // a live cell that isn't alive in the comparison
+	pixelPreview = uint8(255)
+)
 
 func (ls *LifeSim) CreateRenderer() fyne.WidgetRenderer {
 	return widget.NewSimpleRenderer(ls.drawingSurface)
@@ -75,6 +122,12 @@ func NewLifeSim(menuUpdateCallback func()) *LifeSim {
 	sim := &LifeSim{}
 	sim.Game = golife.NewGame()
 	sim.Game.SetHistorySize(Config.HistorySize())
+	sim.Rule = ConwayRule
+	sim.PaintState = 1
+	sim.EditTool = editToolSelect
+	sim.Edits = NewEditHistory(defaultMaxEdits, defaultMaxEditCells)
+	sim.menuUpdate = menuUpdateCallback
+	sim.Detector = NewPeriodDetector()
 	sim.drawingSurface = container.NewWithoutLayout()
 	sim.ResizeToFit()
 	sim.State = binding.NewInt()
@@ -90,8 +143,11 @@ func NewLifeSim(menuUpdateCallback func()) *LifeSim {
 	sim.EditMode.Set(sim.Game.Size() == 0)
 	sim.ExtendBaseWidget(sim)
 	sim.Dirty = true
-	sim.screenCells = make([]bool, 1)
-	sim.rasterCellColor = color.White
+	sim.screenCells = make([]uint8, 1)
+	sim.rasterStateColors = []color.Color{color.Black, color.White}
+	sim.rasterPreviewColor = color.White
+	sim.rasterDiffColor = color.White
+	sim.rasterGridColor = color.White
 	sim.rasterBgColor = color.Black
 	sim.raster = canvas.NewRasterWithPixels(func(x, y, w, h int) color.Color {
 		if sim.screenCols == 0 || sim.screenRows == 0 || w == 0 || h == 0 {
@@ -99,8 +155,18 @@ func NewLifeSim(menuUpdateCallback func()) *LifeSim {
 		}
 		px := x * sim.screenCols / w
 		py := y * sim.screenRows / h
-		if px >= 0 && px < sim.screenCols && py >= 0 && py < sim.screenRows && sim.screenCells[py*sim.screenCols+px] {
-			return sim.rasterCellColor
+		if px >= 0 && px < sim.screenCols && py >= 0 && py < sim.screenRows {
+			stateColors := sim.rasterStateColors
+			switch pixel := sim.screenCells[py*sim.screenCols+px]; {
+			case pixel == pixelPreview:
+				return sim.rasterPreviewColor
+			case pixel == pixelDiff:
+				return sim.rasterDiffColor
+			case pixel == pixelGrid:
+				return sim.rasterGridColor
+			case pixel != pixelEmpty && int(pixel) < len(stateColors):
+				return stateColors[pixel]
+			}
 		}
 		return sim.rasterBgColor
 	})
@@ -109,6 +175,9 @@ func NewLifeSim(menuUpdateCallback func()) *LifeSim {
 	sim.background = canvas.NewRectangle(color.Black)
 	sim.cellPool = make([]fyne.CanvasObject, 0, 256)
 	sim.poolStyle = ""
+	sim.selectionRect = newOverlayRect()
+	sim.pasteRect = newOverlayRect()
+	sim.previewPool = make([]fyne.CanvasObject, 0, 16)
 	return sim
 }
 
@@ -203,7 +272,30 @@ func (ls *LifeSim) Dragged(e *fyne.DragEvent) {
 		return
 	}
 
-	// Normal single-finger pan
+	// The comparison can't be edited, so it only ever pans both sims
+	if ls.leader != nil {
+		ls.leader.pan(e)
+		return
+	}
+
+	// In edit mode a single-finger drag selects a rectangle of cells,
+	// or paints with the brush, rather than panning.
+	if ls.IsEditable() && !ls.IsPasting() {
+		if ls.usingBrush() {
+			ls.brushStroke(e)
+		} else if isShapeTool(ls.EditTool) {
+			ls.dragShape(e)
+		} else {
+			ls.dragSelect(e)
+		}
+		return
+	}
+
+	ls.pan(e)
+}
+
+// pan moves the viewport to follow a drag (or scroll) event.
+func (ls *LifeSim) pan(e *fyne.DragEvent) {
 	ls.SetAutoZoom(false)
 	dx, dy := e.Dragged.Components()
 	cells_x := dx / ls.Scale
@@ -227,6 +319,9 @@ func (ls *LifeSim) DragEnd() {
 	// that the gesture has ended.
 	ls.pinchFingers = 0
 	ls.pinchActive = false
+	ls.dragSelecting = false
+	ls.endStroke()
+	ls.placeShape()
 }
 
 func pointDist(a, b fyne.Position) float32 {
@@ -236,31 +331,43 @@ func pointDist(a, b fyne.Position) float32 {
 }
 
 func (ls *LifeSim) Tapped(e *fyne.PointEvent) {
-	if ls.IsEditable() {
-		// Slightly non-obvious, but the upper left
-		// corner of the dislay box is not  necessarily
-		// aligned at the uppper left corner, but the
-		// center of the display box is always the same
-		// as the center of the window
-		windowSize := ls.drawingSurface.Size()
-		windowCenter_x := windowSize.Width / 2.0
-		windowCenter_y := windowSize.Height / 2.0
-		boxCenter_x := (ls.BoxDisplayMax.X + ls.BoxDisplayMin.X) / 2.0
-		boxCenter_y := (ls.BoxDisplayMax.Y + ls.BoxDisplayMin.Y) / 2.0
-		x, y := e.Position.Components()
-		cell_x := golife.Coord(math.Floor(float64((x-windowCenter_x)/ls.Scale + boxCenter_x + 0.5)))
-		cell_y := golife.Coord(math.Floor(float64((y-windowCenter_y)/ls.Scale + boxCenter_y + 0.5)))
-		cell := golife.Cell{cell_x, cell_y}
-		if ls.Game.HasCell(cell) {
-			ls.Game.RemoveCell(cell)
-		} else {
-			ls.Game.AddCell(cell)
+	if ls.IsEditable() && ls.leader == nil {
+		if ls.IsPasting() {
+			ls.movePasteTo(e.Position)
+			ls.PlacePaste()
+			return
 		}
+		if ls.HasSelection() {
+			// A tap outside of a drag just dismisses the selection
+			ls.ClearSelection()
+			return
+		}
+		if ls.usingBrush() {
+			ls.brushDab(e.Position)
+			return
+		}
+		ls.SyncPopulation()
+		cell := ls.CellAt(e.Position)
+		paint := ls.PaintState
+		if !ls.Rule.IsGenerations() || int(paint) >= ls.Rule.States {
+			paint = 1
+		}
+		oldState := ls.CellState(cell)
+		newState := paint
+		if oldState == paint {
+			newState = 0
+		}
+		ls.SetCellState(cell, newState)
+		ls.RecordEdit(CellStateEdit(cell, oldState, newState))
 		ls.Dirty = true
 	}
 }
 
 func (ls *LifeSim) Scrolled(se *fyne.ScrollEvent) {
+	if ls.leader != nil {
+		ls.leader.Scrolled(se)
+		return
+	}
 	if Config.ScrollAsZoom() {
 		// Slightly non-obvious... this will zoom in if DY is negative
 		// and zoom out if DY is positive.  -0.25 was chosen to taste
@@ -274,8 +381,55 @@ func (ls *LifeSim) Scrolled(se *fyne.ScrollEvent) {
 	} else {
 		// We're going to treat this as equivalent to a Dragged event
 		de := &fyne.DragEvent{PointEvent: se.PointEvent, Dragged: se.Scrolled}
-		ls.Dragged(de)
+		ls.pan(de)
+	}
+}
+
+// Step moves the game on under the sim's rule, timing the step and
+// recording its stats for the chart
+func (ls *LifeSim) Step() {
+	start := time.Now()
+	ls.step()
+	ls.LastStepTime = time.Since(start)
+	ls.recordStats(start.Sub(ls.statsMeasured) >= chartUpdateCadence)
+}
+
+func (ls *LifeSim) step() {
+	if ls.UsingHashLife() {
+		// the detector needs every generation's population, which HashLife doesn't give us
+		ls.Detector.Reset()
+		ls.stepHashLife()
+		return
+	}
+	if ls.Detector.lastGeneration != ls.Game.Generation {
+		ls.observe() // start from the generation we're stepping from
+	}
+	ls.dyingHistory = append(ls.dyingHistory, ls.Dying)
+	ls.Dying = ls.Rule.Next(ls.Game, ls.Dying)
+	// keep the dying history in step with the game's history
+	if len(ls.dyingHistory) > len(ls.Game.History) {
+		ls.dyingHistory = ls.dyingHistory[len(ls.dyingHistory)-len(ls.Game.History):]
 	}
+	ls.observe()
+}
+
+func (ls *LifeSim) observe() {
+	if ls.Detector.Observe(ls.Game.Generation, ls.Game.Population, ls.Dying) {
+		ls.justSettled.Store(true)
+	}
+}
+
+// JustSettled reports whether the pattern was found to be repeating since
+// the last time this was called.
+func (ls *LifeSim) JustSettled() bool {
+	return ls.justSettled.Swap(false)
+}
+
+// Pattern returns the sim's game, rule and dying cells bundled together.
+// The pattern shares its contents with the sim, it isn't a copy.
+func (ls *LifeSim) Pattern() *Pattern {
+	ls.SyncPopulation()
+	return &Pattern{Game: ls.Game, Rule: ls.Rule, Dying: ls.Dying}
 }
 
 func (ls *LifeSim) GetGameInfo() (string, string) {
@@ -335,6 +489,7 @@ func (ls *LifeSim) StateLabel() string {
 }
 
 func (ls *LifeSim) Draw() {
+	ls.followLeader()
 	ls.AutoZoom()
 
 	windowSize := ls.drawingSurface.Size()
@@ -360,6 +515,12 @@ func (ls *LifeSim) Draw() {
 	defer ls.drawLock.Unlock()
 
 	population := ls.Game.Population // saving the current population in case the underlying population changes during draw
+	dying := ls.Dying
+	var tree *HashLife // while HashLife is ahead of the population we draw straight from it
+	if ls.treeAhead {
+		tree = ls.hashLife
+	}
+	differs := ls.cellDiffers() // nil unless highlighting differences from the comparison
 
 	displayWidth := ls.BoxDisplayMax.X - ls.BoxDisplayMin.X + float32(1.0)
 	displayHeight := ls.BoxDisplayMax.Y - ls.BoxDisplayMin.Y + float32(1.0)
@@ -371,18 +532,45 @@ func (ls *LifeSim) Draw() {
 
 	windowCenter := fyne.NewPos(windowSize.Width/2.0, windowSize.Height/2.0)
 
+	viewMin := golife.Cell{X: golife.Coord(math.Floor(float64(displayCenter.X - windowCenter.X/ls.Scale - 1))),
+		Y: golife.Coord(math.Floor(float64(displayCenter.Y - windowCenter.Y/ls.Scale - 1)))}
+	viewMax := golife.Cell{X: golife.Coord(math.Ceil(float64(displayCenter.X + windowCenter.X/ls.Scale + 1))),
+		Y: golife.Coord(math.Ceil(float64(displayCenter.Y + windowCenter.Y/ls.Scale + 1)))}
+
+	overlay := ls.newGridOverlay(windowSize, windowCenter, displayCenter) // nil unless showing the grid or rulers
+
+	// forEachLive calls fn for the live cells.  When drawing from the
+	// HashLife tree, only the visible part is visited, and blocks no
+	// bigger than minSize are passed along whole instead of cell by cell.
+	forEachLive := func(minSize golife.Coord, fn func(cell golife.Cell, size golife.Coord)) {
+		if tree != nil {
+			tree.Visit(viewMin, viewMax, minSize, func(x, y, size golife.Coord) {
+				fn(golife.Cell{X: x, Y: y}, size)
+			})
+			return
+		}
+		for cell := range population {
+			fn(cell, 1)
+		}
+	}
+
 	if ls.Scale >= glyphScaleThreshold {
 		// Glyph path: individual canvas objects per cell.
 		// Used at high zoom where fewer cells are visible and visual quality matters.
 		cellSize := fyne.NewSize(ls.Scale*0.9, ls.Scale*0.9)
 		bgColor := Config.BackgroundColor()
 		cellColor := ls.ModeColor()
+		diffColor := Config.DiffCellColor()
+		stateColors := make([]color.Color, max(ls.Rule.States, 2))
+		for state := range stateColors {
+			stateColors[state] = ls.StateColor(uint8(state))
+		}
 
 		if ls.GlyphStyle != ls.poolStyle {
 			ls.cellPool = ls.cellPool[:0]
 			ls.poolStyle = ls.GlyphStyle
 		}
-		for len(ls.cellPool) < len(population) {
+		growPool := func() {
 			switch ls.GlyphStyle {
 			case "Rectangle", "RoundedRectangle":
 				ls.cellPool = append(ls.cellPool, canvas.NewRectangle(cellColor))
@@ -397,17 +585,47 @@ func (ls *LifeSim) Draw() {
 		type cellPos struct {
 			obj fyne.CanvasObject
 			pos fyne.Position
+			clr color.Color
 		}
-		visible := make([]cellPos, 0, len(population))
+		visible := make([]cellPos, 0, min(ls.CellCount(), len(ls.cellPool))+len(dying))
 		poolIdx := 0
-		for cell := range population {
+		addVisible := func(cell golife.Cell, clr color.Color) {
 			window_x := windowCenter.X + ls.Scale*(float32(cell.X)-displayCenter.X) - ls.Scale/2.0
 			window_y := windowCenter.Y + ls.Scale*(float32(cell.Y)-displayCenter.Y) - ls.Scale/2.0
 			if window_x >= -ls.Scale && window_y >= -ls.Scale && window_x < windowSize.Width+ls.Scale && window_y < windowSize.Height+ls.Scale {
-				visible = append(visible, cellPos{ls.cellPool[poolIdx], fyne.NewPos(window_x+ls.Scale/20, window_y+ls.Scale/20)})
+				if poolIdx >= len(ls.cellPool) {
+					growPool()
+				}
+				visible = append(visible, cellPos{ls.cellPool[poolIdx], fyne.NewPos(window_x+ls.Scale/20, window_y+ls.Scale/20), clr})
 				poolIdx++
 			}
 		}
+		for cell, state := range dying {
+			if int(state) < len(stateColors) {
+				addVisible(cell, stateColors[state])
+			}
+		}
+		forEachLive(1, func(cell golife.Cell, _ golife.Coord) {
+			if differs != nil && differs(cell) {
+				addVisible(cell, diffColor)
+			} else {
+				addVisible(cell, cellColor)
+			}
+		})
+
+		// The paste and shape previews are drawn as translucent rectangles on top of the cells
+		previewColor := withAlpha(cellColor, 128)
+		preview := make([]cellPos, 0, len(ls.pasteBuffer)+len(ls.shapePreview))
+		ls.forEachPreview(func(cell golife.Cell) {
+			window_x := windowCenter.X + ls.Scale*(float32(cell.X)-displayCenter.X) - ls.Scale/2.0
+			window_y := windowCenter.Y + ls.Scale*(float32(cell.Y)-displayCenter.Y) - ls.Scale/2.0
+			if window_x >= -ls.Scale && window_y >= -ls.Scale && window_x < windowSize.Width+ls.Scale && window_y < windowSize.Height+ls.Scale {
+				if len(preview) >= len(ls.previewPool) {
+					ls.previewPool = append(ls.previewPool, canvas.NewRectangle(previewColor))
+				}
+				preview = append(preview, cellPos{ls.previewPool[len(preview)], fyne.NewPos(window_x+ls.Scale/20, window_y+ls.Scale/20), previewColor})
+			}
+		})
 
 		// All canvas mutations must happen on the main goroutine.
 		fyne.Do(func() {
@@ -417,27 +635,42 @@ func (ls *LifeSim) Draw() {
 
 			newObjects := make([]fyne.CanvasObject, 0, len(visible)+1)
 			newObjects = append(newObjects, ls.background)
+			if overlay != nil {
+				newObjects = append(newObjects, ls.grid.lineObjects(overlay)...)
+			}
 
 			for _, cp := range visible {
 				switch ls.GlyphStyle {
 				case "Rectangle":
 					rect := cp.obj.(*canvas.Rectangle)
-					rect.FillColor = cellColor
+					rect.FillColor = cp.clr
 					rect.CornerRadius = 0
 				case "RoundedRectangle":
 					rect := cp.obj.(*canvas.Rectangle)
-					rect.FillColor = cellColor
+					rect.FillColor = cp.clr
 					rect.CornerRadius = ls.Scale / 5.0
 				case "Circle":
-					cp.obj.(*canvas.Circle).FillColor = cellColor
+					cp.obj.(*canvas.Circle).FillColor = cp.clr
 				default:
-					cp.obj.(*canvas.Line).StrokeColor = cellColor
+					cp.obj.(*canvas.Line).StrokeColor = cp.clr
 				}
 				cp.obj.Resize(cellSize)
 				cp.obj.Move(cp.pos)
 				newObjects = append(newObjects, cp.obj)
 			}
 
+			for _, cp := range preview {
+				cp.obj.(*canvas.Rectangle).FillColor = cp.clr
+				cp.obj.Resize(cellSize)
+				cp.obj.Move(cp.pos)
+				newObjects = append(newObjects, cp.obj)
+			}
+
+			newObjects = append(newObjects, ls.selectionOverlay()...)
+			if overlay != nil {
+				newObjects = append(newObjects, ls.grid.rulerObjects(overlay)...)
+			}
+
 			ls.drawingSurface.RemoveAll()
 			for _, obj := range newObjects {
 				ls.drawingSurface.Add(obj)
@@ -448,42 +681,73 @@ func (ls *LifeSim) Draw() {
 		// Raster path: single canvas object for all cells.
 		// Used at low zoom where many cells may be visible and efficiency matters.
 		ls.rasterBgColor = Config.BackgroundColor()
-		ls.rasterCellColor = ls.ModeColor()
+		stateColors := make([]color.Color, max(ls.Rule.States, 2))
+		for state := range stateColors {
+			stateColors[state] = ls.StateColor(uint8(state))
+		}
+		ls.rasterStateColors = stateColors
+		ls.rasterPreviewColor = withAlpha(stateColors[1], 128)
+		ls.rasterDiffColor = Config.DiffCellColor()
 
 		newCols := int(windowSize.Width) + 2
 		newRows := int(windowSize.Height) + 2
 		if newCols != ls.screenCols || newRows != ls.screenRows {
 			ls.screenCols = newCols
 			ls.screenRows = newRows
-			ls.screenCells = make([]bool, newCols*newRows)
+			ls.screenCells = make([]uint8, newCols*newRows)
 		} else {
 			clear(ls.screenCells)
 		}
 
-		for cell := range population {
+		// markBlock fills in the pixels covered by a square block of
+		// cells.  Later calls win, unless onlyEmpty is set.
+		markBlock := func(cell golife.Cell, size golife.Coord, value uint8, onlyEmpty bool) {
+			extent := ls.Scale * float32(size)
 			window_x := windowCenter.X + ls.Scale*(float32(cell.X)-displayCenter.X) - ls.Scale/2.0
 			window_y := windowCenter.Y + ls.Scale*(float32(cell.Y)-displayCenter.Y) - ls.Scale/2.0
 
-			if window_x >= -ls.Scale && window_y >= -ls.Scale && window_x < windowSize.Width+ls.Scale && window_y < windowSize.Height+ls.Scale {
+			if window_x >= -extent && window_y >= -extent && window_x < windowSize.Width+ls.Scale && window_y < windowSize.Height+ls.Scale {
 				x0 := max(0, int(window_x+ls.Scale/20))
 				y0 := max(0, int(window_y+ls.Scale/20))
-				x1 := min(ls.screenCols-1, int(window_x+ls.Scale*0.9))
-				y1 := min(ls.screenRows-1, int(window_y+ls.Scale*0.9))
+				x1 := min(ls.screenCols-1, int(window_x+extent-ls.Scale/10))
+				y1 := min(ls.screenRows-1, int(window_y+extent-ls.Scale/10))
 				for py := y0; py <= y1; py++ {
 					for px := x0; px <= x1; px++ {
-						ls.screenCells[py*ls.screenCols+px] = true
+						if !onlyEmpty || ls.screenCells[py*ls.screenCols+px] == pixelEmpty {
+							ls.screenCells[py*ls.screenCols+px] = value
+						}
 					}
 				}
 			}
 		}
 
-		if !ls.usingRaster {
-			fyne.Do(func() {
-				ls.drawingSurface.Objects = []fyne.CanvasObject{ls.raster}
-			})
-			ls.usingRaster = true
+		for cell, state := range dying {
+			markBlock(cell, 1, state, false)
+		}
+		// there's no point breaking the tree down past a pixel
+		forEachLive(golife.Coord(max(1, 1/ls.Scale)), func(cell golife.Cell, size golife.Coord) {
+			if differs != nil && differs(cell) {
+				markBlock(cell, size, pixelDiff, false)
+			} else {
+				markBlock(cell, size, pixelCell, false)
+			}
+		})
+		ls.forEachPreview(func(cell golife.Cell) {
+			markBlock(cell, 1, pixelPreview, true)
+		})
+		if overlay != nil {
+			ls.rasterGridColor = overlay.Color
+			ls.markGridPixels(overlay)
 		}
-		fyne.Do(func() { ls.raster.Resize(windowSize) })
+
+		fyne.Do(func() {
+			ls.drawingSurface.Objects = append([]fyne.CanvasObject{ls.raster}, ls.selectionOverlay()...)
+			if overlay != nil {
+				ls.drawingSurface.Objects = append(ls.drawingSurface.Objects, ls.grid.rulerObjects(overlay)...)
+			}
+			ls.raster.Resize(windowSize)
+		})
+		ls.usingRaster = true
 	}
 
 	ls.drawPending.Store(true)
@@ -558,7 +822,7 @@ func (ls *LifeSim) AutoZoom() {
 		return
 	}
 
-	gameCoordMin, gameCoordMax := ls.Game.Population.BoundingBox()
+	gameCoordMin, gameCoordMax := ls.viewBoundingBox()
 
 	if float32(gameCoordMin.X) < ls.BoxDisplayMin.X {
 		ls.BoxDisplayMin.X = float32(gameCoordMin.X)
@@ -578,7 +842,7 @@ func (ls *LifeSim) AutoZoom() {
 }
 
 func (ls *LifeSim) ResizeToFit() {
-	boxMin, boxMax := ls.Game.Population.BoundingBox()
+	boxMin, boxMax := ls.viewBoundingBox()
 	newMin, newMax := fyne.NewPos(float32(boxMin.X), float32(boxMin.Y)), fyne.NewPos(float32(boxMax.X), float32(boxMax.Y))
 	ls.SetDisplayBox(newMin, newMax)
 	ls.Dirty = true
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+
+	"github.com/pneumaticdeath/golife"
+
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Random fills, or "soups", fill a rectangle with live cells at random.
+// The random numbers come from a PCG seeded with a number that's written
+// into the game's comments, so the same soup can be made again by
+// filling the same size of rectangle with the same settings and seed.
+// Symmetric soups only pick cells for part of the rectangle and copy
+// them to the rest, and the symmetries that rotate by 90 degrees need a
+// square, so they use the biggest square in the middle of the rectangle.
+
+const (
+	symmetryNone = "None"
+	symmetryC2   = "C2" // rotating by 180 degrees
+	symmetryC4   = "C4" // rotating by 90 degrees
+	symmetryD4   = "D4" // mirrored left to right and top to bottom
+	symmetryD8   = "D8" // rotating by 90 degrees and mirrored
+)
+
+var symmetries = []string{symmetryNone, symmetryC2, symmetryC4, symmetryD4, symmetryD8}
+
+const maxFillCells = 4000000 // the most cells filled at once, by a random fill or a filled rectangle
+
+// checkFillSize returns an error if a rectangle is too big to fill at
+// once.  It divides rather than multiplies, so huge rectangles can't
+// overflow.
+func checkFillSize(width, height int64) error {
+	if width > maxFillCells/height {
+		return fmt.Errorf("Can fill at most %d cells at a time, try a smaller area", maxFillCells)
+	}
+	return nil
+}
+
+type Soup struct {
+	Min           golife.Cell // upper left corner
+	Width, Height int
+	Density       float64 // the chance of each cell being alive, from 0 to 1
+	Symmetry      string
+	Seed          uint64
+}
+
+func symmetryNeedsSquare(symmetry string) bool {
+	return symmetry == symmetryC4 || symmetry == symmetryD8
+}
+
+// squared shrinks the soup to a square in the middle if its symmetry needs one
+func (s Soup) squared() Soup {
+	if !symmetryNeedsSquare(s.Symmetry) || s.Width == s.Height {
+		return s
+	}
+	side := min(s.Width, s.Height)
+	s.Min.X += golife.Coord((s.Width - side) / 2)
+	s.Min.Y += golife.Coord((s.Height - side) / 2)
+	s.Width, s.Height = side, side
+	return s
+}
+
+// orbit returns the cells that the symmetry says must match the cell at
+// x, y (counted from the upper left corner)
+func (s Soup) orbit(x, y int) [][2]int {
+	w, h := s.Width-1, s.Height-1
+	switch s.Symmetry {
+	case symmetryC2:
+		return [][2]int{{x, y}, {w - x, h - y}}
+	case symmetryC4:
+		return [][2]int{{x, y}, {w - y, x}, {w - x, h - y}, {y, h - x}}
+	case symmetryD4:
+		return [][2]int{{x, y}, {w - x, y}, {x, h - y}, {w - x, h - y}}
+	case symmetryD8:
+		return [][2]int{{x, y}, {w - y, x}, {w - x, h - y}, {y, h - x},
+			{w - x, y}, {x, h - y}, {y, x}, {w - y, h - x}}
+	}
+	return [][2]int{{x, y}}
+}
+
+// Cells returns which of the soup's cells are alive, keyed by their
+// position in the game.  Every cell in the rectangle is included, so
+// filling replaces whatever was there.
+func (s Soup) Cells() map[golife.Cell]bool {
+	s = s.squared()
+	rng := rand.New(rand.NewPCG(s.Seed, 0))
+	cells := make(map[golife.Cell]bool, s.Width*s.Height)
+	for y := 0; y < s.Height; y++ {
+		for x := 0; x < s.Width; x++ {
+			first := golife.Cell{X: s.Min.X + golife.Coord(x), Y: s.Min.Y + golife.Coord(y)}
+			if _, done := cells[first]; done {
+				continue
+			}
+			alive := rng.Float64() < s.Density
+			for _, xy := range s.orbit(x, y) {
+				cells[golife.Cell{X: s.Min.X + golife.Coord(xy[0]), Y: s.Min.Y + golife.Coord(xy[1])}] = alive
+			}
+		}
+	}
+	return cells
+}
+
+// Comment describes the soup well enough to make it again
+func (s Soup) Comment() string {
+	s = s.squared()
+	return fmt.Sprintf("C random fill: seed %d, density %g%%, symmetry %s, %dx%d at %d,%d",
+		s.Seed, s.Density*100, s.Symmetry, s.Width, s.Height, s.Min.X, s.Min.Y)
+}
+
+func (s Soup) check() error {
+	if s.Width <= 0 || s.Height <= 0 {
+		return errors.New("The rectangle to fill is empty")
+	}
+	if err := checkFillSize(int64(s.Width), int64(s.Height)); err != nil {
+		return err
+	}
+	if s.Density < 0 || s.Density > 1 {
+		return errors.New("Density has to be between 0 and 100%")
+	}
+	return nil
+}
+
+// FillSoup replaces the cells in the soup's rectangle, as one edit, and
+// notes how it was made in the comments.
+func (lc *LifeContainer) FillSoup(soup Soup) {
+	lc.Control.StopSim()
+	sim := lc.Sim
+	single := []golife.Cell{{X: 0, Y: 0}}
+	sim.startStroke(soup.Min)
+	for cell, alive := range soup.Cells() {
+		if alive {
+			sim.paintBrush(cell, 1, single)
+		} else if sim.CellState(cell) != 0 {
+			sim.paintBrush(cell, 0, single)
+		}
+	}
+	edit := sim.finishStroke()
+	edit.Comments = []string{soup.Comment()}
+	sim.Game.Comments = append(sim.Game.Comments, edit.Comments...)
+	sim.RecordEdit(edit)
+	if sim.IsAutoZoom() {
+		sim.ResizeToFit()
+	}
+	sim.Dirty = true
+}
+
+// viewRectangle returns the whole cells inside the viewport
+func (ls *LifeSim) viewRectangle() (golife.Cell, int, int) {
+	minCell := golife.Cell{X: golife.Coord(math.Ceil(float64(ls.BoxDisplayMin.X))), Y: golife.Coord(math.Ceil(float64(ls.BoxDisplayMin.Y)))}
+	maxCell := golife.Cell{X: golife.Coord(math.Floor(float64(ls.BoxDisplayMax.X))), Y: golife.Coord(math.Floor(float64(ls.BoxDisplayMax.Y)))}
+	return minCell, int(maxCell.X-minCell.X) + 1, int(maxCell.Y-minCell.Y) + 1
+}
+
+// ShowRandomFillDialog asks where and how to fill, and fills
+func ShowRandomFillDialog(lc *LifeContainer) {
+	const (
+		regionView      = "Current view"
+		regionRectangle = "Rectangle"
+	)
+	intValidator := func(text string) error {
+		_, err := strconv.Atoi(text)
+		return err
+	}
+
+	// the rectangle starts out as the selection, if there is one
+	rectMin, width, height := lc.Sim.viewRectangle()
+	if minCell, maxCell, ok := lc.Sim.Selection(); ok {
+		rectMin, width, height = minCell, int(maxCell.X-minCell.X)+1, int(maxCell.Y-minCell.Y)+1
+	}
+	newIntEntry := func(value int) *widget.Entry {
+		entry := widget.NewEntry()
+		entry.SetText(strconv.Itoa(value))
+		entry.Validator = intValidator
+		return entry
+	}
+	xEntry, yEntry := newIntEntry(int(rectMin.X)), newIntEntry(int(rectMin.Y))
+	widthEntry, heightEntry := newIntEntry(width), newIntEntry(height)
+	rectEntries := container.NewGridWithColumns(4, xEntry, yEntry, widthEntry, heightEntry)
+
+	regionSelector := widget.NewRadioGroup([]string{regionView, regionRectangle}, func(selection string) {
+		for _, entry := range []*widget.Entry{xEntry, yEntry, widthEntry, heightEntry} {
+			if selection == regionRectangle {
+				entry.Enable()
+			} else {
+				entry.Disable()
+			}
+		}
+	})
+	regionSelector.Required = true
+	if _, _, ok := lc.Sim.Selection(); ok {
+		regionSelector.SetSelected(regionRectangle)
+	} else {
+		regionSelector.SetSelected(regionView)
+	}
+
+	densityEntry := widget.NewEntry()
+	densityEntry.SetText("50")
+	densityEntry.Validator = func(text string) error {
+		_, err := strconv.ParseFloat(text, 64)
+		return err
+	}
+	symmetrySelector := widget.NewSelect(symmetries, nil)
+	symmetrySelector.SetSelected(symmetryNone)
+	seedEntry := widget.NewEntry()
+	seedEntry.SetPlaceHolder("Random")
+	seedEntry.Validator = func(text string) error {
+		if strings.TrimSpace(text) == "" {
+			return nil
+		}
+		_, err := strconv.ParseUint(strings.TrimSpace(text), 10, 64)
+		return err
+	}
+
+	formItems := []*widget.FormItem{
+		widget.NewFormItem("Fill", regionSelector),
+		widget.NewFormItem("X, Y, width, height", rectEntries),
+		widget.NewFormItem("Density (%)", densityEntry),
+		widget.NewFormItem("Symmetry", symmetrySelector),
+		widget.NewFormItem("Seed", seedEntry),
+	}
+	dialog.ShowForm("Random fill", "Fill", "Cancel", formItems, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		soup := Soup{Symmetry: symmetrySelector.Selected, Seed: rand.Uint64()}
+		if regionSelector.Selected == regionRectangle {
+			x, _ := strconv.Atoi(xEntry.Text)
+			y, _ := strconv.Atoi(yEntry.Text)
+			soup.Min = golife.Cell{X: golife.Coord(x), Y: golife.Coord(y)}
+			soup.Width, _ = strconv.Atoi(widthEntry.Text)
+			soup.Height, _ = strconv.Atoi(heightEntry.Text)
+		} else {
+			soup.Min, soup.Width, soup.Height = lc.Sim.viewRectangle()
+		}
+		density, _ := strconv.ParseFloat(densityEntry.Text, 64)
+		soup.Density = density / 100
+		if seedText := strings.TrimSpace(seedEntry.Text); seedText != "" {
+			soup.Seed, _ = strconv.ParseUint(seedText, 10, 64)
+		}
+		if err := soup.check(); err != nil {
+			dialog.ShowError(err, mainWindow)
+			return
+		}
+		lc.FillSoup(soup)
+	}, mainWindow)
+}
@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestSoupCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		soup Soup
+		ok   bool
+	}{
+		{"small", Soup{Width: 10, Height: 20, Density: 0.5}, true},
+		{"at the limit", Soup{Width: maxFillCells / 2, Height: 2, Density: 0.5}, true},
+		{"empty", Soup{Width: 0, Height: 20, Density: 0.5}, false},
+		{"too many cells", Soup{Width: maxFillCells, Height: 2, Density: 0.5}, false},
+		{"big enough to overflow", Soup{Width: 1 << 32, Height: 1 << 32, Density: 0.5}, false},
+		{"density too high", Soup{Width: 10, Height: 10, Density: 1.5}, false},
+	}
+	for _, test := range tests {
+		if err := test.soup.check(); (err == nil) != test.ok {
+			t.Errorf("%s: got error %v", test.name, err)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"sync"
+
+	"github.com/pneumaticdeath/golife"
+	"github.com/pneumaticdeath/golife/examples"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// The stamp palette sits beside the sim and lists objects to drop into
+// the game: the examples that are small enough, and stamps the user has
+// made from a selection.  Tapping a stamp arms it, so each tap on the
+// board places a copy until Escape is pressed, and dragging one onto the
+// board places it just once.  Either way it's a paste preview until it's
+// placed, so it can be rotated and flipped first.
+
+const maxStockStampSize = 64 // examples bigger than this on a side aren't offered as stamps
+
+type Stamp struct {
+	Name      string
+	Cells     golife.Population // normalized to 0,0
+	User      bool              // made by the user rather than one of the examples
+	thumbnail image.Image       // drawn when it's first needed
+}
+
+func NewStamp(name string, cells golife.Population, user bool) *Stamp {
+	return &Stamp{Name: name, Cells: normalizePopulation(cells), User: user}
+}
+
+func (stamp *Stamp) Thumbnail() image.Image {
+	if stamp.thumbnail != nil {
+		return stamp.thumbnail
+	}
+	game := golife.NewGame()
+	for cell := range stamp.Cells {
+		game.AddCell(cell)
+	}
+	img, err := renderThumbnail(&Pattern{Game: game, Rule: ConwayRule})
+	if err != nil {
+		fyne.LogError("Unable to render thumbnail for "+stamp.Name, err)
+		return nil
+	}
+	stamp.thumbnail = img
+	return img
+}
+
+// stockStamps are the examples small enough to stamp.  Reading them
+// takes a moment, so it waits until a palette is first opened, and then
+// they're shared by every tab.
+var stockStamps = sync.OnceValue(func() []*Stamp {
+	var stamps []*Stamp
+	for _, ex := range examples.ListExamples() {
+		game := examples.LoadExample(ex)
+		if game == nil || game.Size() == 0 {
+			continue
+		}
+		minCell, maxCell := game.Population.BoundingBox()
+		if maxCell.X-minCell.X >= maxStockStampSize || maxCell.Y-minCell.Y >= maxStockStampSize {
+			continue
+		}
+		name := strings.TrimSpace(strings.TrimSuffix(ex.Title, ".rle")) // untitled examples go by their file name
+		stamps = append(stamps, NewStamp(name, game.Population, false))
+	}
+	return stamps
+})
+
+var userStamps []*Stamp // nil until they're read from the preferences
+
+func (c ConfigT) UserStamps() []*Stamp {
+	if userStamps != nil {
+		return userStamps
+	}
+	userStamps = make([]*Stamp, 0)
+	for _, rle := range c.app.Preferences().StringList(userStampsKey) {
+		pattern, err := ReadRLE(strings.NewReader(rle))
+		if err != nil {
+			fyne.LogError("Unable to read stamp", err)
+			continue
+		}
+		userStamps = append(userStamps, NewStamp(pattern.Game.Name, pattern.Game.Population, true))
+	}
+	return userStamps
+}
+
+func (c ConfigT) SetUserStamps(stamps []*Stamp) {
+	userStamps = stamps
+	rles := make([]string, 0, len(stamps))
+	for _, stamp := range stamps {
+		game := golife.NewGame()
+		game.Name = stamp.Name
+		for cell := range stamp.Cells {
+			game.AddCell(cell)
+		}
+		var writer strings.Builder
+		if err := WriteRLE(&Pattern{Game: game, Rule: ConwayRule}, &writer); err != nil {
+			fyne.LogError("Unable to save stamp "+stamp.Name, err)
+			continue
+		}
+		rles = append(rles, writer.String())
+	}
+	c.app.Preferences().SetStringList(userStampsKey, rles)
+}
+
+// AllStamps returns the user's stamps followed by the stock ones
+func AllStamps() []*Stamp {
+	user := Config.UserStamps()
+	stamps := make([]*Stamp, 0, len(user)+len(stockStamps()))
+	return append(append(stamps, user...), stockStamps()...)
+}
+
+// ArmStamp starts a paste of the stamp that stays after each placement
+func (ls *LifeSim) ArmStamp(stamp *Stamp) {
+	ls.StartPaste(stamp.Cells)
+	ls.stamping = true
+}
+
+type StampPalette struct {
+	widget.BaseWidget
+	life     *LifeSim
+	stamps   []*Stamp // what the list is showing, nil until it's first shown
+	list     *widget.List
+	content  *fyne.Container
+	dragging bool // whether a stamp being dragged from the palette is over the sim
+}
+
+func NewStampPalette(sim *LifeSim) *StampPalette {
+	sp := &StampPalette{life: sim}
+	sp.list = widget.NewList(func() int {
+		if sp.stamps == nil {
+			sp.stamps = AllStamps()
+		}
+		return len(sp.stamps)
+	}, func() fyne.CanvasObject {
+		return newStampRow(sp)
+	}, func(id widget.ListItemID, obj fyne.CanvasObject) {
+		if id < len(sp.stamps) {
+			obj.(*stampRow).SetStamp(sp.stamps[id])
+		}
+	})
+	addButton := widget.NewButtonWithIcon("Add Selection", theme.ContentAddIcon(), sp.addSelection)
+	sp.content = container.NewBorder(nil, addButton, nil, nil, sp.list)
+
+	sp.ExtendBaseWidget(sp)
+	return sp
+}
+
+func (sp *StampPalette) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(sp.content)
+}
+
+// Reload updates the list, for when stamps have been added or deleted
+// in another tab
+func (sp *StampPalette) Reload() {
+	if sp.stamps != nil {
+		sp.stamps = AllStamps()
+		sp.list.Refresh()
+	}
+}
+
+func (sp *StampPalette) ensureEditMode() {
+	if !sp.life.IsEditable() {
+		sp.life.SetEditMode(true)
+	}
+}
+
+func (sp *StampPalette) arm(stamp *Stamp) {
+	sp.ensureEditMode()
+	sp.life.ArmStamp(stamp)
+}
+
+// dragTo shows the stamp where it would be dropped while it's over the
+// sim, given the absolute position of the drag
+func (sp *StampPalette) dragTo(stamp *Stamp, pos fyne.Position) {
+	pos = pos.Subtract(fyne.CurrentApp().Driver().AbsolutePositionForObject(sp.life))
+	size := sp.life.Size()
+	if pos.X < 0 || pos.Y < 0 || pos.X >= size.Width || pos.Y >= size.Height {
+		if sp.dragging {
+			sp.life.CancelPaste()
+			sp.dragging = false
+		}
+		return
+	}
+	if !sp.dragging {
+		sp.ensureEditMode()
+		sp.life.StartPaste(stamp.Cells)
+		sp.dragging = true
+	}
+	sp.life.movePasteTo(pos)
+}
+
+// drop places a dragged stamp if it was let go of over the sim
+func (sp *StampPalette) drop() {
+	if sp.dragging {
+		sp.life.PlacePaste()
+		sp.dragging = false
+	}
+}
+
+func (sp *StampPalette) addSelection() {
+	cells := sp.life.SelectedCells()
+	if len(cells) == 0 {
+		dialog.ShowInformation("Nothing selected", "Select some cells in edit mode to make a stamp of them.", mainWindow)
+		return
+	}
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText(fmt.Sprintf("Stamp %d", len(Config.UserStamps())+1))
+	formItems := []*widget.FormItem{widget.NewFormItem("Name", nameEntry)}
+	dialog.ShowForm("Add stamp", "Add", "Cancel", formItems, func(confirmed bool) {
+		name := strings.TrimSpace(nameEntry.Text)
+		if !confirmed || name == "" {
+			return
+		}
+		Config.SetUserStamps(append(Config.UserStamps(), NewStamp(name, cells, true)))
+		sp.Reload()
+	}, mainWindow)
+}
+
+func (sp *StampPalette) deleteStamp(stamp *Stamp) {
+	dialog.ShowConfirm("Delete "+stamp.Name, fmt.Sprintf("Are you sure you want to delete the stamp %s?", stamp.Name), func(yes bool) {
+		if !yes {
+			return
+		}
+		stamps := make([]*Stamp, 0, len(Config.UserStamps()))
+		for _, other := range Config.UserStamps() {
+			if other != stamp {
+				stamps = append(stamps, other)
+			}
+		}
+		Config.SetUserStamps(stamps)
+		sp.Reload()
+	}, mainWindow)
+}
+
+// stampRow is one stamp in the palette, which can be tapped or dragged
+type stampRow struct {
+	widget.BaseWidget
+	palette      *StampPalette
+	stamp        *Stamp
+	thumbnail    *canvas.Image
+	name         *widget.Label
+	deleteButton *widget.Button
+	content      *fyne.Container
+}
+
+func newStampRow(sp *StampPalette) *stampRow {
+	row := &stampRow{palette: sp}
+	row.thumbnail = canvas.NewImageFromImage(nil)
+	row.thumbnail.FillMode = canvas.ImageFillContain
+	row.thumbnail.ScaleMode = canvas.ImageScalePixels
+	row.thumbnail.SetMinSize(fyne.NewSize(thumbnailSize, thumbnailSize))
+	row.name = widget.NewLabel("")
+	row.deleteButton = widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		if row.stamp != nil {
+			sp.deleteStamp(row.stamp)
+		}
+	})
+	row.deleteButton.Importance = widget.LowImportance
+	row.content = container.New(layout.NewHBoxLayout(), row.thumbnail, row.name, layout.NewSpacer(), row.deleteButton)
+	row.ExtendBaseWidget(row)
+	return row
+}
+
+func (row *stampRow) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(row.content)
+}
+
+func (row *stampRow) SetStamp(stamp *Stamp) {
+	row.stamp = stamp
+	row.thumbnail.Image = stamp.Thumbnail()
+	row.thumbnail.Refresh()
+	row.name.SetText(stamp.Name)
+	if stamp.User {
+		row.deleteButton.Show()
+	} else {
+		row.deleteButton.Hide()
+	}
+}
+
+func (row *stampRow) Tapped(_ *fyne.PointEvent) {
+	if row.stamp != nil {
+		row.palette.arm(row.stamp)
+	}
+}
+
+func (row *stampRow) Dragged(e *fyne.DragEvent) {
+	if row.stamp != nil {
+		row.palette.dragTo(row.stamp, fyne.CurrentApp().Driver().AbsolutePositionForObject(row).Add(e.Position))
+	}
+}
+
+func (row *stampRow) DragEnd() {
+	row.palette.drop()
+}
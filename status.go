@@ -20,6 +20,8 @@ type StatusBar struct {
 	control             *ControlBar
 	GenerationDisplay   *widget.Label
 	CellCountDisplay    *widget.Label
+	RuleDisplay         *widget.Label
+	PeriodDisplay       *widget.Label
 	HistorySizeDisplay  *widget.Label
 	ScaleDisplay        *widget.Label
 	LastStepTimeDisplay *widget.Label
@@ -34,13 +36,16 @@ type StatusBar struct {
 func NewStatusBar(sim *LifeSim, cb *ControlBar) *StatusBar {
 	genDisp := widget.NewLabel("")
 	cellCountDisp := widget.NewLabel("")
+	ruleDisp := widget.NewLabel("")
+	periodDisp := widget.NewLabel("")
 	histSizeDisp := widget.NewLabel("")
 	scaleDisp := widget.NewLabel("")
 	lastStepTimeDisp := widget.NewLabel("")
 	lastDrawTimeDisp := widget.NewLabel("")
 	targetGPSDisp := widget.NewLabel("")
 	actualGPSDisp := widget.NewLabel("")
-	statBar := &StatusBar{life: sim, control: cb, GenerationDisplay: genDisp, CellCountDisplay: cellCountDisp,
+	statBar := &StatusBar{life: sim, control: cb, GenerationDisplay: genDisp, CellCountDisplay: cellCountDisp, RuleDisplay: ruleDisp,
+		PeriodDisplay:      periodDisp,
 		HistorySizeDisplay: histSizeDisp, ScaleDisplay: scaleDisp, LastStepTimeDisplay: lastStepTimeDisp,
 		LastDrawTimeDisplay: lastDrawTimeDisp, TargetGPSDisplay: targetGPSDisp,
 		ActualGPSDisplay: actualGPSDisp, UpdateCadence: 50.0 * time.Millisecond, ClockRunning: true}
@@ -48,7 +53,9 @@ func NewStatusBar(sim *LifeSim, cb *ControlBar) *StatusBar {
 	if fyne.CurrentDevice().IsMobile() {
 		statBar.bar = container.New(layout.NewVBoxLayout(),
 			container.New(layout.NewHBoxLayout(), widget.NewLabel("Gen:"), statBar.GenerationDisplay,
-				layout.NewSpacer(), widget.NewLabel("Cells:"), statBar.CellCountDisplay),
+				layout.NewSpacer(), widget.NewLabel("Cells:"), statBar.CellCountDisplay,
+				layout.NewSpacer(), widget.NewLabel("Rule:"), statBar.RuleDisplay),
+			container.New(layout.NewHBoxLayout(), widget.NewLabel("Pattern:"), statBar.PeriodDisplay),
 			container.New(layout.NewHBoxLayout(), widget.NewLabel("Target GPS:"), statBar.TargetGPSDisplay,
 				layout.NewSpacer(), widget.NewLabel("Actual GPS:"), statBar.ActualGPSDisplay))
 	} else {
@@ -56,9 +63,11 @@ func NewStatusBar(sim *LifeSim, cb *ControlBar) *StatusBar {
 			container.New(layout.NewHBoxLayout(), widget.NewLabel("Generation:"), statBar.GenerationDisplay,
 				layout.NewSpacer(), widget.NewLabel("Available history"), statBar.HistorySizeDisplay,
 				layout.NewSpacer(), widget.NewLabel("Live Cells:"), statBar.CellCountDisplay,
+				layout.NewSpacer(), widget.NewLabel("Rule:"), statBar.RuleDisplay,
 				layout.NewSpacer(), widget.NewLabel("Scale:"), statBar.ScaleDisplay),
 			container.New(layout.NewHBoxLayout(), widget.NewLabel("Last step time:"), statBar.LastStepTimeDisplay,
 				layout.NewSpacer(), widget.NewLabel("Last draw time:"), statBar.LastDrawTimeDisplay,
+				layout.NewSpacer(), widget.NewLabel("Pattern:"), statBar.PeriodDisplay,
 				layout.NewSpacer(), widget.NewLabel("Target GPS:"), statBar.TargetGPSDisplay,
 				widget.NewLabel("Actual GPS:"), statBar.ActualGPSDisplay))
 	}
@@ -81,7 +90,9 @@ func (statBar *StatusBar) CreateRenderer() fyne.WidgetRenderer {
 
 func (statBar *StatusBar) Update() {
 	statBar.GenerationDisplay.SetText(fmt.Sprintf("%d", statBar.life.Game.Generation))
-	statBar.CellCountDisplay.SetText(fmt.Sprintf("%d", statBar.life.Game.Size()))
+	statBar.CellCountDisplay.SetText(fmt.Sprintf("%d", statBar.life.CellCount()))
+	statBar.RuleDisplay.SetText(statBar.life.Rule.Name())
+	statBar.PeriodDisplay.SetText(statBar.life.Detector.Result.String())
 	statBar.HistorySizeDisplay.SetText(fmt.Sprintf("%d of %d", len(statBar.life.Game.History), statBar.life.Game.HistorySize))
 	statBar.ScaleDisplay.SetText(fmt.Sprintf("%.3f", statBar.life.Scale))
 	statBar.LastStepTimeDisplay.SetText(fmt.Sprintf("%7v", statBar.life.LastStepTime))
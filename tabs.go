@@ -8,6 +8,7 @@ import (
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 )
 
@@ -18,19 +19,24 @@ import (
 type LifeTabs struct {
 	widget.BaseWidget
 
-	DocTabs *container.DocTabs
+	DocTabs   *container.DocTabs
+	runStates map[*LifeContainer]RunState // what each tab's badge is showing
+}
+
+// gameTitle is the name of the game shown on its tab
+func gameTitle(game *golife.Game) string {
+	if game.Name != "" {
+		return game.Name
+	} else if game.Filename != "" {
+		return filepath.Base(game.Filename)
+	}
+	return "Blank Game"
 }
 
 func NewLifeTabs(lc *LifeContainer) *LifeTabs {
 	lt := &LifeTabs{}
 
-	title := "Blank Game"
-	if lc.Sim.Game.Name != "" {
-		title = lc.Sim.Game.Name
-	} else if lc.Sim.Game.Filename != "" {
-		title = filepath.Base(lc.Sim.Game.Filename)
-	}
-	ti := container.NewTabItem(title, lc)
+	ti := container.NewTabItem(gameTitle(lc.Sim.Game), lc)
 	lt.DocTabs = container.NewDocTabs(ti)
 
 	lt.ExtendBaseWidget(lt)
@@ -79,24 +85,89 @@ func (lt *LifeTabs) GetLifeContainters() []*LifeContainer {
 }
 
 func (lt *LifeTabs) NewTab(lc *LifeContainer) {
-	title := "Blank Game"
-	if lc.Sim.Game.Name != "" {
-		title = lc.Sim.Game.Name
-	} else if lc.Sim.Game.Filename != "" {
-		title = filepath.Base(lc.Sim.Game.Filename)
-	}
-	lt.DocTabs.Append(container.NewTabItem(title, lc))
+	lt.DocTabs.Append(container.NewTabItem(gameTitle(lc.Sim.Game), lc))
 	lt.DocTabs.SelectIndex(len(lt.DocTabs.Items) - 1)
 }
 
 func (lt *LifeTabs) SetCurrentGame(game *golife.Game) {
 	lc := lt.CurrentLifeContainer()
 	lc.SetGame(game)
-	title := "Blank Game"
-	if game.Name != "" {
-		title = game.Name
-	} else if game.Filename != "" {
-		title = filepath.Base(game.Filename)
+	lt.DocTabs.Selected().Text = gameTitle(game)
+}
+
+// SetCurrentPattern replaces the game in the current tab along with its
+// rule and dying cells.
+func (lt *LifeTabs) SetCurrentPattern(pattern *Pattern) {
+	lc := lt.CurrentLifeContainer()
+	lc.SetPattern(pattern)
+	lt.DocTabs.Selected().Text = gameTitle(pattern.Game)
+}
+
+// UpdateRunStates lets each tab know whether it's hidden, and updates the
+// badges that show which tabs are running.
+func (lt *LifeTabs) UpdateRunStates() {
+	current := lt.CurrentLifeContainer()
+	runStates := make(map[*LifeContainer]RunState, len(lt.DocTabs.Items))
+	changed := false
+	for _, item := range lt.DocTabs.Items {
+		lc, ok := item.Content.(*LifeContainer)
+		if !ok {
+			continue
+		}
+		lc.Control.SetHidden(lc != current)
+		state := lc.Control.RunState()
+		runStates[lc] = state
+		if state == lt.runStates[lc] {
+			continue
+		}
+		switch state {
+		case runActive:
+			item.Icon = theme.MediaPlayIcon()
+		case runHeld:
+			item.Icon = theme.MediaPauseIcon()
+		default:
+			item.Icon = nil
+		}
+		changed = true
+	}
+	lt.runStates = runStates
+	if changed {
+		lt.DocTabs.Refresh()
+	}
+}
+
+// RunAll starts every tab, and keeps them running in the background
+// until they're paused
+func (lt *LifeTabs) RunAll() {
+	for _, lc := range lt.GetLifeContainters() {
+		lc.Control.RunAll()
+	}
+	lt.UpdateRunStates()
+}
+
+func (lt *LifeTabs) PauseAll() {
+	for _, lc := range lt.GetLifeContainters() {
+		lc.Control.StopSim()
+	}
+	lt.UpdateRunStates()
+}
+
+// RedrawAll has every sim redrawn, for when something they all show has changed
+func (lt *LifeTabs) RedrawAll() {
+	for _, lc := range lt.GetLifeContainters() {
+		lc.Sim.Dirty = true
+		if compare := lc.Sim.Compare; compare != nil {
+			compare.Dirty = true
+		}
+	}
+}
+
+// UpdateTitle resets the title of the current tab to match its game
+func (lt *LifeTabs) UpdateTitle() {
+	lc := lt.CurrentLifeContainer()
+	if lc == nil {
+		return
 	}
-	lt.DocTabs.Selected().Text = title
+	lt.DocTabs.Selected().Text = gameTitle(lc.Sim.Game)
+	lt.DocTabs.Refresh()
 }
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"github.com/pneumaticdeath/golife"
+)
+
+// Transforms rotate, reflect or move a rectangular region of cells.  Each
+// one maps a cell inside the box bounded by minCell and maxCell (inclusive)
+// to its new location.  Rotations and flips keep the upper left corner of
+// the box where it was, so four rotations in the same direction (or two
+// flips) always get you back where you started.
+
+type CellTransform func(cell, minCell, maxCell golife.Cell) golife.Cell
+
+func RotateCW(cell, minCell, maxCell golife.Cell) golife.Cell {
+	return golife.Cell{X: minCell.X + maxCell.Y - cell.Y, Y: minCell.Y + cell.X - minCell.X}
+}
+
+func RotateCCW(cell, minCell, maxCell golife.Cell) golife.Cell {
+	return golife.Cell{X: minCell.X + cell.Y - minCell.Y, Y: minCell.Y + maxCell.X - cell.X}
+}
+
+func FlipHorizontal(cell, minCell, maxCell golife.Cell) golife.Cell {
+	return golife.Cell{X: minCell.X + maxCell.X - cell.X, Y: cell.Y}
+}
+
+func FlipVertical(cell, minCell, maxCell golife.Cell) golife.Cell {
+	return golife.Cell{X: cell.X, Y: minCell.Y + maxCell.Y - cell.Y}
+}
+
+// Translate returns a transform that moves every cell by dx,dy
+func Translate(dx, dy golife.Coord) CellTransform {
+	return func(cell, _, _ golife.Cell) golife.Cell {
+		return golife.Cell{X: cell.X + dx, Y: cell.Y + dy}
+	}
+}
+
+// TransformPopulation returns a new population where the cells inside the
+// box have been transformed and the cells outside of it are left alone.
+func TransformPopulation(pop golife.Population, minCell, maxCell golife.Cell, transform CellTransform) golife.Population {
+	newPop := make(golife.Population, len(pop))
+	for cell := range pop {
+		if inBox(cell, minCell, maxCell) {
+			newPop[transform(cell, minCell, maxCell)] = true
+		} else {
+			newPop[cell] = true
+		}
+	}
+	return newPop
+}
+
+// TransformDying does the same for the dying cells, dropping any that end
+// up under a live cell of the transformed population.
+func TransformDying(dying DyingCells, pop golife.Population, minCell, maxCell golife.Cell, transform CellTransform) DyingCells {
+	if len(dying) == 0 {
+		return dying
+	}
+	newDying := make(DyingCells, len(dying))
+	for cell, state := range dying {
+		if inBox(cell, minCell, maxCell) {
+			cell = transform(cell, minCell, maxCell)
+		}
+		if !pop[cell] {
+			newDying[cell] = state
+		}
+	}
+	return newDying
+}
+
+// TransformBox returns where the corners of the box end up after the transform.
+func TransformBox(minCell, maxCell golife.Cell, transform CellTransform) (golife.Cell, golife.Cell) {
+	corner1 := transform(minCell, minCell, maxCell)
+	corner2 := transform(maxCell, minCell, maxCell)
+	return golife.Cell{X: min(corner1.X, corner2.X), Y: min(corner1.Y, corner2.Y)},
+		golife.Cell{X: max(corner1.X, corner2.X), Y: max(corner1.Y, corner2.Y)}
+}
+
+// ApplyTransform transforms the pattern waiting to be pasted if there is
+// one, otherwise the current selection, and if there is no selection the
+// whole population.
+func (ls *LifeSim) ApplyTransform(transform CellTransform) {
+	if ls.IsPasting() {
+		minCell, maxCell := ls.pasteBuffer.BoundingBox()
+		ls.pasteBuffer = normalizePopulation(TransformPopulation(ls.pasteBuffer, minCell, maxCell, transform))
+		ls.Dirty = true
+		return
+	}
+
+	ls.SyncPopulation()
+	minCell, maxCell, selected := ls.Selection()
+	if !selected {
+		if ls.Game.Size() == 0 {
+			return
+		}
+		minCell, maxCell = ls.Game.Population.BoundingBox()
+	}
+
+	before, dyingBefore := ls.Game.Population, ls.Dying
+	ls.Game.Population = TransformPopulation(before, minCell, maxCell, transform)
+	ls.Dying = TransformDying(dyingBefore, ls.Game.Population, minCell, maxCell, transform)
+	edit := DiffPopulations(before, ls.Game.Population)
+	edit.Dying = DiffDying(dyingBefore, ls.Dying)
+	ls.RecordEdit(edit)
+	if selected {
+		ls.SetSelection(TransformBox(minCell, maxCell, transform))
+	}
+	ls.Dirty = true
+	if ls.IsAutoZoom() {
+		ls.ResizeToFit()
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/pneumaticdeath/golife"
+
+	"fyne.io/fyne/v2/test"
+)
+
+func TestApplyTransformMovesDyingCells(t *testing.T) {
+	InitConfig(test.NewApp())
+	sim := NewLifeSim(func() {})
+	sim.Rule, _ = ParseRule("B2/S/C3")
+	sim.Game.AddCell(golife.Cell{X: 0, Y: 0})
+	sim.Dying = DyingCells{{X: 2, Y: 0}: 2, {X: 5, Y: 5}: 2}
+	sim.SetSelection(golife.Cell{X: 0, Y: 0}, golife.Cell{X: 2, Y: 0})
+	sim.ApplyTransform(FlipHorizontal)
+
+	if !sim.Game.HasCell(golife.Cell{X: 2, Y: 0}) || sim.Game.Size() != 1 {
+		t.Errorf("got live cells %v, want just 2,0", sim.Game.Population)
+	}
+	flipped := DyingCells{{X: 0, Y: 0}: 2, {X: 5, Y: 5}: 2}
+	if !maps.Equal(sim.Dying, flipped) {
+		t.Errorf("got dying cells %v, want %v", sim.Dying, flipped)
+	}
+
+	pattern := sim.Pattern()
+	if !sim.Edits.Undo(pattern) {
+		t.Fatal("couldn't undo the flip")
+	}
+	unflipped := DyingCells{{X: 2, Y: 0}: 2, {X: 5, Y: 5}: 2}
+	if !sim.Game.HasCell(golife.Cell{X: 0, Y: 0}) || !maps.Equal(pattern.Dying, unflipped) {
+		t.Errorf("undoing left live cells %v and dying cells %v", sim.Game.Population, pattern.Dying)
+	}
+}
+
+func TestTransformDyingUnderLiveCell(t *testing.T) {
+	dying := DyingCells{{X: 0, Y: 0}: 2}
+	pop := golife.Population{{X: 1, Y: 0}: true}
+	if got := TransformDying(dying, pop, golife.Cell{X: 0, Y: 0}, golife.Cell{X: 0, Y: 0}, Translate(1, 0)); len(got) != 0 {
+		t.Errorf("got %v, want the dying cell dropped under the live one", got)
+	}
+}
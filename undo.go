@@ -0,0 +1,317 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/pneumaticdeath/golife"
+)
+
+// The EditHistory keeps track of changes the user makes to a pattern
+// (toggling cells, pasting, transforming, clearing or loading) so they
+// can be undone and redone.  It is completely separate from the
+// generation history kept by golife.Game, which only lets you step
+// back through generations.
+//
+// Most edits are stored as the set of cells added and removed, which is
+// cheap for the common case of toggling a cell or two.  Edits that
+// replace the whole game (clearing or loading a pattern) keep the pattern
+// that was replaced instead.  Cell based edits are only valid at the
+// generation they were made in, so if the sim has been stepped since,
+// the user has to step back to that generation before undoing.
+
+const (
+	defaultMaxEdits     = 100
+	defaultMaxEditCells = 1000000
+)
+
+type Edit struct {
+	Added      []golife.Cell // cells that were born because of the edit
+	Removed    []golife.Cell // cells that were killed by the edit
+	Dying      []StateChange // changes to the dying cells of a Generations rule
+	Comments   []string      // comments added to the game by the edit
+	Generation int           // generation the edit was made at
+	Before     *Pattern      // for whole game edits, the pattern that was replaced
+	After      *Pattern      // for whole game edits, the pattern that replaced it
+	size       int           // the edit's cost when it was stored, so it comes off the total as it went on
+}
+
+// A StateChange records a dying cell's state before and after an edit,
+// where 0 means it wasn't dying.
+type StateChange struct {
+	Cell   golife.Cell
+	Before uint8
+	After  uint8
+}
+
+func (e *Edit) IsGameEdit() bool {
+	return e.Before != nil || e.After != nil
+}
+
+// patternCost is how many cells a pattern holds on to.  Its game's
+// generation history is included unless it's the live game, which the
+// sim owns and keeps adding to.
+func patternCost(pattern *Pattern, live *golife.Game) int {
+	if pattern == nil {
+		return 0
+	}
+	cost := pattern.Game.Size() + len(pattern.Dying)
+	if pattern.Game != live {
+		for _, population := range pattern.Game.History {
+			cost += len(population)
+		}
+	}
+	return cost
+}
+
+// cost is a rough measure of how much memory an edit holds on to, in
+// cells, while the sim is running the live game
+func (e *Edit) cost(live *golife.Game) int {
+	return len(e.Added) + len(e.Removed) + len(e.Dying) + len(e.Comments) + 1 + patternCost(e.Before, live) + patternCost(e.After, live)
+}
+
+// liveGame is the game the sim is running once the edit has been made
+func (e *Edit) liveGame() *golife.Game {
+	if e.After != nil {
+		return e.After.Game
+	}
+	return nil
+}
+
+// resize works the edit's cost out again once the sim is running the
+// live game, for game edits that have just been undone or redone
+func (h *EditHistory) resize(edit *Edit, live *golife.Game) {
+	h.cells -= edit.size
+	edit.size = edit.cost(live)
+	h.cells += edit.size
+}
+
+// IsEmpty reports whether the edit doesn't actually change anything
+func (e *Edit) IsEmpty() bool {
+	return !e.IsGameEdit() && len(e.Added) == 0 && len(e.Removed) == 0 && len(e.Dying) == 0 && len(e.Comments) == 0
+}
+
+// CellStateEdit builds an edit that changes a single cell from one state
+// to another.
+func CellStateEdit(cell golife.Cell, before, after uint8) Edit {
+	var edit Edit
+	if before == after {
+		return edit
+	}
+	if before == 1 {
+		edit.Removed = []golife.Cell{cell}
+	}
+	if after == 1 {
+		edit.Added = []golife.Cell{cell}
+	}
+	if before > 1 || after > 1 {
+		change := StateChange{Cell: cell}
+		if before > 1 {
+			change.Before = before
+		}
+		if after > 1 {
+			change.After = after
+		}
+		edit.Dying = []StateChange{change}
+	}
+	return edit
+}
+
+// DiffPopulations builds an edit that turns the before population into
+// the after population.
+func DiffPopulations(before, after golife.Population) Edit {
+	var edit Edit
+	for cell := range after {
+		if !before[cell] {
+			edit.Added = append(edit.Added, cell)
+		}
+	}
+	for cell := range before {
+		if !after[cell] {
+			edit.Removed = append(edit.Removed, cell)
+		}
+	}
+	return edit
+}
+
+// DiffDying lists the changes that turn the before dying cells into the
+// after ones.
+func DiffDying(before, after DyingCells) []StateChange {
+	var changes []StateChange
+	for cell, state := range after {
+		if before[cell] != state {
+			changes = append(changes, StateChange{Cell: cell, Before: before[cell], After: state})
+		}
+	}
+	for cell, state := range before {
+		if _, ok := after[cell]; !ok {
+			changes = append(changes, StateChange{Cell: cell, Before: state})
+		}
+	}
+	return changes
+}
+
+type EditHistory struct {
+	undo     []Edit
+	redo     []Edit
+	maxEdits int // the most edits kept on the undo stack
+	maxCells int // rough cap on the number of cells all the stored edits can reference
+	cells    int // current number of cells referenced
+}
+
+func NewEditHistory(maxEdits, maxCells int) *EditHistory {
+	return &EditHistory{undo: make([]Edit, 0, 10), redo: make([]Edit, 0, 10), maxEdits: maxEdits, maxCells: maxCells}
+}
+
+// Record pushes a new edit on the undo stack.  Making a new edit
+// invalidates anything that was previously undone, so the redo stack is
+// cleared.
+func (h *EditHistory) Record(edit Edit) {
+	if edit.IsEmpty() {
+		return
+	}
+	for _, old := range h.redo {
+		h.cells -= old.size
+	}
+	h.redo = h.redo[:0]
+	edit.size = edit.cost(edit.liveGame())
+	h.undo = append(h.undo, edit)
+	h.cells += edit.size
+	h.trim()
+}
+
+// trim drops the oldest edits until we're back under our limits, but
+// always keeps the most recent one.
+func (h *EditHistory) trim() {
+	drop := 0
+	for len(h.undo)-drop > 1 && (len(h.undo)-drop > h.maxEdits || h.cells > h.maxCells) {
+		h.cells -= h.undo[drop].size
+		drop++
+	}
+	if drop > 0 {
+		h.undo = append(h.undo[:0], h.undo[drop:]...)
+	}
+}
+
+// ForgetCellEdits drops the edits that change cells, for when the game
+// they were made to has been replaced without an edit of its own.
+func (h *EditHistory) ForgetCellEdits() {
+	forget := func(edits []Edit) []Edit {
+		kept := edits[:0]
+		for _, edit := range edits {
+			if edit.IsGameEdit() {
+				kept = append(kept, edit)
+			} else {
+				h.cells -= edit.size
+			}
+		}
+		return kept
+	}
+	h.undo = forget(h.undo)
+	h.redo = forget(h.redo)
+}
+
+func editApplies(edit *Edit, game *golife.Game, forward bool) bool {
+	if edit.IsGameEdit() {
+		if forward {
+			return edit.Before != nil && game == edit.Before.Game
+		}
+		return edit.After != nil && game == edit.After.Game
+	}
+	return game.Generation == edit.Generation
+}
+
+// CanUndo reports whether the most recent edit can be undone on the game
+// as it currently stands.
+func (h *EditHistory) CanUndo(game *golife.Game) bool {
+	return len(h.undo) > 0 && editApplies(&h.undo[len(h.undo)-1], game, false)
+}
+
+func (h *EditHistory) CanRedo(game *golife.Game) bool {
+	return len(h.redo) > 0 && editApplies(&h.redo[len(h.redo)-1], game, true)
+}
+
+func setDyingState(pattern *Pattern, cell golife.Cell, state uint8) {
+	if state == 0 {
+		delete(pattern.Dying, cell)
+		return
+	}
+	if pattern.Dying == nil {
+		pattern.Dying = make(DyingCells)
+	}
+	pattern.Dying[cell] = state
+}
+
+// Undo reverses the most recent edit on the pattern, which is changed in
+// place.  If the edit replaced the whole game, the pattern is set back
+// to the one that was replaced.  Returns false if there was nothing that
+// could be undone.
+func (h *EditHistory) Undo(pattern *Pattern) bool {
+	if !h.CanUndo(pattern.Game) {
+		return false
+	}
+	edit := h.undo[len(h.undo)-1]
+	h.undo = h.undo[:len(h.undo)-1]
+	h.redo = append(h.redo, edit)
+	if edit.IsGameEdit() {
+		h.resize(&h.redo[len(h.redo)-1], edit.Before.Game)
+		*pattern = *edit.Before
+		return true
+	}
+	for _, cell := range edit.Added {
+		pattern.Game.RemoveCell(cell)
+	}
+	for _, cell := range edit.Removed {
+		pattern.Game.AddCell(cell)
+	}
+	for _, change := range edit.Dying {
+		setDyingState(pattern, change.Cell, change.Before)
+	}
+	// the comments the edit added are the last ones, unless they've been
+	// changed since
+	comments := pattern.Game.Comments
+	if kept := len(comments) - len(edit.Comments); kept >= 0 && slices.Equal(comments[kept:], edit.Comments) {
+		pattern.Game.Comments = comments[:kept]
+	}
+	return true
+}
+
+// Redo reapplies the most recently undone edit.
+func (h *EditHistory) Redo(pattern *Pattern) bool {
+	if !h.CanRedo(pattern.Game) {
+		return false
+	}
+	edit := h.redo[len(h.redo)-1]
+	h.redo = h.redo[:len(h.redo)-1]
+	h.undo = append(h.undo, edit)
+	if edit.IsGameEdit() {
+		h.resize(&h.undo[len(h.undo)-1], edit.After.Game)
+		*pattern = *edit.After
+		return true
+	}
+	for _, cell := range edit.Removed {
+		pattern.Game.RemoveCell(cell)
+	}
+	for _, cell := range edit.Added {
+		pattern.Game.AddCell(cell)
+	}
+	for _, change := range edit.Dying {
+		setDyingState(pattern, change.Cell, change.After)
+	}
+	pattern.Game.Comments = append(pattern.Game.Comments, edit.Comments...)
+	return true
+}
+
+// RecordEdit adds an edit made to the sim's current game to its history.
+func (ls *LifeSim) RecordEdit(edit Edit) {
+	edit.Generation = ls.Game.Generation
+	ls.Edits.Record(edit)
+	ls.Detector.Reset()
+	ls.newLineage()
+	ls.menuUpdate()
+}
+
+// RecordDiff records the difference between the population before an
+// edit and the game's current population.
+func (ls *LifeSim) RecordDiff(before golife.Population) {
+	ls.RecordEdit(DiffPopulations(before, ls.Game.Population))
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/pneumaticdeath/golife"
+)
+
+func newTestPattern(cells ...golife.Cell) *Pattern {
+	game := golife.NewGame()
+	game.AddCells(cells)
+	return &Pattern{Game: game, Rule: ConwayRule}
+}
+
+func sortedCells(cells []golife.Cell) []golife.Cell {
+	sorted := slices.Clone(cells)
+	slices.SortFunc(sorted, func(a, b golife.Cell) int {
+		if a.X != b.X {
+			return int(a.X - b.X)
+		}
+		return int(a.Y - b.Y)
+	})
+	return sorted
+}
+
+func TestCellStateEdit(t *testing.T) {
+	cell := golife.Cell{X: 3, Y: -4}
+	tests := []struct {
+		name          string
+		before, after uint8
+		added         int
+		removed       int
+		dying         []StateChange
+	}{
+		{"no change", 1, 1, 0, 0, nil},
+		{"birth", 0, 1, 1, 0, nil},
+		{"death", 1, 0, 0, 1, nil},
+		{"live to dying", 1, 2, 0, 1, []StateChange{{Cell: cell, Before: 0, After: 2}}},
+		{"dying to live", 3, 1, 1, 0, []StateChange{{Cell: cell, Before: 3, After: 0}}},
+		{"dying to empty", 2, 0, 0, 0, []StateChange{{Cell: cell, Before: 2, After: 0}}},
+		{"dying to dying", 2, 3, 0, 0, []StateChange{{Cell: cell, Before: 2, After: 3}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			edit := CellStateEdit(cell, test.before, test.after)
+			if len(edit.Added) != test.added || len(edit.Removed) != test.removed {
+				t.Errorf("got %d added and %d removed, want %d and %d", len(edit.Added), len(edit.Removed), test.added, test.removed)
+			}
+			if !slices.Equal(edit.Dying, test.dying) {
+				t.Errorf("got dying changes %v, want %v", edit.Dying, test.dying)
+			}
+			if edit.IsEmpty() != (test.before == test.after) {
+				t.Errorf("IsEmpty() = %v", edit.IsEmpty())
+			}
+		})
+	}
+}
+
+func TestDiffPopulations(t *testing.T) {
+	a, b, c := golife.Cell{X: 0, Y: 0}, golife.Cell{X: 1, Y: 0}, golife.Cell{X: -5, Y: 7}
+	tests := []struct {
+		name           string
+		before, after  golife.Population
+		added, removed []golife.Cell
+	}{
+		{"both empty", golife.Population{}, golife.Population{}, nil, nil},
+		{"same", golife.Population{a: true, b: true}, golife.Population{a: true, b: true}, nil, nil},
+		{"added", golife.Population{a: true}, golife.Population{a: true, b: true, c: true}, []golife.Cell{c, b}, nil},
+		{"removed", golife.Population{a: true, c: true}, golife.Population{}, nil, []golife.Cell{c, a}},
+		{"both", golife.Population{a: true, b: true}, golife.Population{b: true, c: true}, []golife.Cell{c}, []golife.Cell{a}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			edit := DiffPopulations(test.before, test.after)
+			if got := sortedCells(edit.Added); !slices.Equal(got, test.added) {
+				t.Errorf("added %v, want %v", got, test.added)
+			}
+			if got := sortedCells(edit.Removed); !slices.Equal(got, test.removed) {
+				t.Errorf("removed %v, want %v", got, test.removed)
+			}
+		})
+	}
+}
+
+func TestEditApplies(t *testing.T) {
+	before, after := newTestPattern(), newTestPattern()
+	other := newTestPattern()
+	other.Game.Generation = 5
+	gameEdit := &Edit{Before: before, After: after}
+	cellEdit := &Edit{Added: []golife.Cell{{X: 1, Y: 1}}, Generation: 5}
+	tests := []struct {
+		name    string
+		edit    *Edit
+		game    *golife.Game
+		forward bool
+		want    bool
+	}{
+		{"game edit undone on its result", gameEdit, after.Game, false, true},
+		{"game edit undone on another game", gameEdit, other.Game, false, false},
+		{"game edit undone on what it replaced", gameEdit, before.Game, false, false},
+		{"game edit redone on what it replaced", gameEdit, before.Game, true, true},
+		{"game edit redone on its result", gameEdit, after.Game, true, false},
+		{"cell edit at its generation", cellEdit, other.Game, false, true},
+		{"cell edit redone at its generation", cellEdit, other.Game, true, true},
+		{"cell edit at another generation", cellEdit, before.Game, false, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := editApplies(test.edit, test.game, test.forward); got != test.want {
+				t.Errorf("editApplies() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestEditHistoryUndoRedo(t *testing.T) {
+	a, b := golife.Cell{X: 0, Y: 0}, golife.Cell{X: 2, Y: 3}
+	pattern := newTestPattern(a)
+	history := NewEditHistory(defaultMaxEdits, defaultMaxEditCells)
+
+	pattern.Game.AddCell(b)
+	history.Record(Edit{Added: []golife.Cell{b}})
+	pattern.Game.RemoveCell(a)
+	history.Record(Edit{Removed: []golife.Cell{a}})
+	history.Record(Edit{}) // empty edits aren't recorded
+
+	steps := []struct {
+		name string
+		op   func(*Pattern) bool
+		ok   bool
+		want []golife.Cell
+	}{
+		{"undo remove", history.Undo, true, []golife.Cell{a, b}},
+		{"undo add", history.Undo, true, []golife.Cell{a}},
+		{"nothing left to undo", history.Undo, false, []golife.Cell{a}},
+		{"redo add", history.Redo, true, []golife.Cell{a, b}},
+		{"redo remove", history.Redo, true, []golife.Cell{b}},
+		{"nothing left to redo", history.Redo, false, []golife.Cell{b}},
+	}
+	for _, step := range steps {
+		if ok := step.op(pattern); ok != step.ok {
+			t.Fatalf("%s: got %v, want %v", step.name, ok, step.ok)
+		}
+		var cells []golife.Cell
+		for cell := range pattern.Game.Population {
+			cells = append(cells, cell)
+		}
+		if got := sortedCells(cells); !slices.Equal(got, step.want) {
+			t.Fatalf("%s: cells %v, want %v", step.name, got, step.want)
+		}
+	}
+
+	// a new edit clears what could be redone
+	history.Undo(pattern)
+	history.Record(Edit{Added: []golife.Cell{{X: 9, Y: 9}}})
+	if history.CanRedo(pattern.Game) {
+		t.Error("redo still possible after a new edit")
+	}
+
+	// cell edits don't apply once the game has moved on
+	pattern.Game.Generation++
+	if history.Undo(pattern) {
+		t.Error("undid a cell edit at a later generation")
+	}
+}
+
+func TestEditHistoryGameEdit(t *testing.T) {
+	before := newTestPattern(golife.Cell{X: 1, Y: 1})
+	after := newTestPattern(golife.Cell{X: 5, Y: 5}, golife.Cell{X: 6, Y: 5})
+	pattern := after.Copy()
+	pattern.Game = after.Game
+	history := NewEditHistory(defaultMaxEdits, defaultMaxEditCells)
+	history.Record(Edit{Before: before, After: after})
+
+	if !history.Undo(pattern) || pattern.Game != before.Game {
+		t.Fatal("undo didn't put back the replaced game")
+	}
+	if !history.Redo(pattern) || pattern.Game != after.Game {
+		t.Fatal("redo didn't put back the replacing game")
+	}
+}
+
+func TestEditHistoryTrim(t *testing.T) {
+	edit := func(cells int) Edit {
+		added := make([]golife.Cell, cells)
+		for i := range added {
+			added[i] = golife.Cell{X: golife.Coord(i)}
+		}
+		return Edit{Added: added}
+	}
+	tests := []struct {
+		name               string
+		maxEdits, maxCells int
+		sizes              []int
+		wantEdits          int
+	}{
+		{"under the limits", 10, 1000, []int{1, 2, 3}, 3},
+		{"too many edits", 2, 1000, []int{1, 2, 3, 4}, 2},
+		{"too many cells", 10, 20, []int{5, 5, 5, 5, 5}, 3},
+		{"one edit bigger than the limit is kept", 10, 20, []int{5, 100}, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			history := NewEditHistory(test.maxEdits, test.maxCells)
+			for _, size := range test.sizes {
+				history.Record(edit(size))
+			}
+			if len(history.undo) != test.wantEdits {
+				t.Errorf("kept %d edits, want %d", len(history.undo), test.wantEdits)
+			}
+			last := history.undo[len(history.undo)-1]
+			if len(last.Added) != test.sizes[len(test.sizes)-1] {
+				t.Error("the most recent edit was dropped")
+			}
+			total := 0
+			for _, kept := range history.undo {
+				total += kept.size
+			}
+			if total != history.cells {
+				t.Errorf("counted %d cells, but the edits hold %d", history.cells, total)
+			}
+		})
+	}
+}
+
+func TestEditCostCountsHistory(t *testing.T) {
+	before := newTestPattern(golife.Cell{X: 0, Y: 0}, golife.Cell{X: 1, Y: 0}, golife.Cell{X: 2, Y: 0})
+	edit := Edit{Before: before}
+	plain := edit.cost(nil)
+	before.Game.SetHistorySize(10)
+	for range 4 {
+		before.Game.Next()
+	}
+	if withHistory := edit.cost(nil); withHistory <= plain {
+		t.Errorf("cost with history %d, no more than %d without", withHistory, plain)
+	}
+	if live := edit.cost(before.Game); live != plain {
+		t.Errorf("cost %d counts the history of the live game, want %d", live, plain)
+	}
+}
+
+func TestEditHistoryCellsStayInStep(t *testing.T) {
+	history := NewEditHistory(2, defaultMaxEditCells)
+	before := newTestPattern(golife.Cell{X: 0, Y: 0})
+	after := newTestPattern(golife.Cell{X: 0, Y: 0}, golife.Cell{X: 1, Y: 0}, golife.Cell{X: 2, Y: 0})
+	after.Game.SetHistorySize(10)
+	history.Record(Edit{Before: before, After: after})
+
+	// the sim runs the new game on, after the edit was stored
+	for range 5 {
+		after.Game.Next()
+	}
+	pattern := after.Copy()
+	pattern.Game = after.Game
+	history.Undo(pattern)
+	history.Redo(pattern)
+	for range 3 {
+		history.Record(Edit{Added: []golife.Cell{{X: 9, Y: 9}}})
+	}
+
+	total := 0
+	for _, kept := range history.undo {
+		total += kept.size
+	}
+	if history.cells != total || total <= 0 {
+		t.Errorf("counted %d cells, but the edits hold %d", history.cells, total)
+	}
+}